@@ -0,0 +1,74 @@
+package changelog
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"strings"
+)
+
+// section is a titled group of commits in the rendered release notes.
+type section struct {
+	Title   string
+	Commits []conventional.Commit
+}
+
+// Generate renders a Markdown section of release notes for version from the conventional commits since the previous
+// tag. Commits are grouped into Breaking Changes, Features, Fixes and Other. Empty groups are left out, so are commits
+// reverted within the range.
+func Generate(version semver.Version, commits []conventional.Commit) string {
+	breaking := section{Title: "Breaking Changes"}
+	features := section{Title: "Features"}
+	fixes := section{Title: "Fixes"}
+	other := section{Title: "Other"}
+
+	for _, c := range conventional.DropReverted(commits) {
+		switch {
+		case c.IsBreaking:
+			breaking.Commits = append(breaking.Commits, c)
+		case c.Type == conventional.Feature:
+			features.Commits = append(features.Commits, c)
+		case c.Type == conventional.Fix:
+			fixes.Commits = append(fixes.Commits, c)
+		default:
+			other.Commits = append(other.Commits, c)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## %s\n", version.String()))
+	for _, s := range []section{breaking, features, fixes, other} {
+		if len(s.Commits) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n### %s\n", s.Title))
+		for _, c := range s.Commits {
+			sb.WriteString(formatCommit(c))
+		}
+	}
+	return sb.String()
+}
+
+// formatCommit renders a single commit as a Markdown list item. Breaking changes are described by their
+// BREAKING CHANGE footer, ie.
+//	- **scope:** add the thing (fb067b1) by John Doe
+func formatCommit(c conventional.Commit) string {
+	var sb strings.Builder
+	sb.WriteString("- ")
+	if c.Scope != "" {
+		sb.WriteString(fmt.Sprintf("**%s:** ", c.Scope))
+	}
+	if c.IsBreaking && c.BreakingDescription != "" {
+		sb.WriteString(c.BreakingDescription)
+	} else {
+		sb.WriteString(c.Title)
+	}
+	if hash := c.ShortHash(); hash != "" {
+		sb.WriteString(fmt.Sprintf(" (%s)", hash))
+	}
+	if c.Author.Name != "" {
+		sb.WriteString(fmt.Sprintf(" by %s", c.Author.Name))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
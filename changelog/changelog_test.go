@@ -0,0 +1,63 @@
+package changelog
+
+import (
+	"github.com/Masterminds/semver"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	author := git.Author{Name: "megatron", Email: "megatron@email.com"}
+	commits := conventional.ParseCommits([]git.Commit{
+		{Subject: "feat(api)!: remove the v1 endpoints", Hash: "aaaaaaaaaaaa", Author: author},
+		{Subject: "feat: add the v2 endpoints", Hash: "bbbbbbbbbbbb", Author: author},
+		{Subject: "fix(db): close idle connections", Hash: "cccccccccccc", Author: author},
+		{Subject: "fix(db): rename the pool option", Hash: "ffffffffffff", Author: author,
+			Body: "BREAKING CHANGE: `pool` is now `maxConnections`"},
+		{Subject: "chore: bump dependencies", Hash: "dddddddddddd", Author: author},
+		{Subject: "This is not a conventional commit", Hash: "eeeeeeeeeeee", Author: author},
+	})
+
+	notes := Generate(*semver.MustParse("v2.0.0"), commits)
+	assert.Equal(t, `## 2.0.0
+
+### Breaking Changes
+- **api:** remove the v1 endpoints (aaaaaaa) by megatron
+- **db:** `+"`pool` is now `maxConnections`"+` (fffffff) by megatron
+
+### Features
+- add the v2 endpoints (bbbbbbb) by megatron
+
+### Fixes
+- **db:** close idle connections (ccccccc) by megatron
+
+### Other
+- bump dependencies (ddddddd) by megatron
+- This is not a conventional commit (eeeeeee) by megatron
+`, notes)
+}
+
+func TestGenerateSkipsReverts(t *testing.T) {
+	commits := conventional.ParseCommits([]git.Commit{
+		{Subject: "Revert \"feat: add the thing\"", Body: "This reverts commit bbbbbbbbbbbb.", Hash: "cccccccccccc"},
+		{Subject: "feat: add the thing", Hash: "bbbbbbbbbbbb"},
+		{Subject: "fix: patch the hole", Hash: "aaaaaaaaaaaa"},
+	})
+
+	notes := Generate(*semver.MustParse("v0.0.2"), commits)
+	assert.Equal(t, "## 0.0.2\n\n### Fixes\n- patch the hole (aaaaaaa)\n", notes)
+}
+
+func TestGenerateSkipsEmptySections(t *testing.T) {
+	commits := conventional.ParseCommits([]git.Commit{
+		{Subject: "fix: patch the hole", Hash: "abc"},
+	})
+
+	notes := Generate(*semver.MustParse("v0.0.2"), commits)
+	assert.Equal(t, "## 0.0.2\n\n### Fixes\n- patch the hole (abc)\n", notes)
+
+	notes = Generate(*semver.MustParse("v0.0.2"), nil)
+	assert.Equal(t, "## 0.0.2\n", notes)
+}
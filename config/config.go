@@ -0,0 +1,158 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// FileNames are the names of the configuration file looked up in the root of a repository, in order.
+var FileNames = []string{".semversioner.yaml", ".semversioner.yml", ".semversioner.toml"}
+
+// Config declares the versioning rules of a repository, ie.
+//	tagPrefix: v
+//	initialVersion: 0.0.0
+//	initialDevelopment: true
+//	type: conventional
+//	bumps:
+//	  major: []
+//	  minor: [feat]
+//	  patch: [fix, perf]
+//	  none: [chore, docs]
+//	branches:
+//	  - pattern: main
+//	  - pattern: develop
+//	    prerelease: beta
+//	    prereleaseCounter: true
+// The same settings can be written in TOML, with a [[branches]] table per branch policy.
+type Config struct {
+	// TagPrefix is the text in front of the semantic version of a tag.
+	TagPrefix string `yaml:"tagPrefix" toml:"tagPrefix"`
+	// InitialVersion is the version the first release is based on.
+	InitialVersion string `yaml:"initialVersion" toml:"initialVersion"`
+	// Type is the release type used when --type is not passed.
+	Type string `yaml:"type" toml:"type"`
+	// Bumps lists the commit types per bump (major, minor, patch or none).
+	Bumps map[string][]string `yaml:"bumps" toml:"bumps"`
+	// Branches are the release policies per branch. The first policy matching the current branch applies.
+	Branches []Branch `yaml:"branches" toml:"branches"`
+	// InitialDevelopment keeps 0.x versions below 1.0.0 until they graduate.
+	InitialDevelopment bool `yaml:"initialDevelopment" toml:"initialDevelopment"`
+}
+
+// Branch is the release policy of the branches matching its Pattern. Empty settings keep the repository defaults.
+type Branch struct {
+	// Pattern is a glob matched against the branch name, ie. feature/*
+	Pattern string `yaml:"pattern" toml:"pattern"`
+	// Type is the release type of the branch, ie. snapshot for feature branches.
+	Type string `yaml:"type" toml:"type"`
+	// Prerelease is the pre-release channel of the branch, ie. beta.
+	Prerelease string `yaml:"prerelease" toml:"prerelease"`
+	// PrereleaseCounter numbers the pre-releases, ie. beta.1, beta.2
+	PrereleaseCounter bool `yaml:"prereleaseCounter" toml:"prereleaseCounter"`
+	// MaxBump is the highest bump allowed on the branch, ie. patch for release/1.x
+	MaxBump string `yaml:"maxBump" toml:"maxBump"`
+}
+
+// BranchPolicy returns the first branch policy whose pattern matches branch. The second return value is false when
+// none matches.
+func (c Config) BranchPolicy(branch string) (Branch, bool, error) {
+	for _, b := range c.Branches {
+		ok, err := path.Match(b.Pattern, branch)
+		if err != nil {
+			return Branch{}, false, fmt.Errorf("invalid branch pattern=%s err=%w", b.Pattern, err)
+		}
+		if ok {
+			return b, true, nil
+		}
+	}
+	return Branch{}, false, nil
+}
+
+// Default returns the configuration used when a repository has no configuration file.
+func Default() Config {
+	rules := conventional.DefaultRules()
+	bumps := make(map[string][]string)
+	for t, b := range rules.Bumps {
+		bumps[b.String()] = append(bumps[b.String()], string(t))
+	}
+	return Config{
+		TagPrefix:      rules.TagPrefix,
+		InitialVersion: rules.InitialVersion,
+		Bumps:          bumps,
+	}
+}
+
+// Load reads the configuration file from the root of the repository in workDir. Settings missing from the file keep
+// their default value, declared bumps replace the default ones entirely, unknown settings are an error. The default
+// configuration is returned when there is no file.
+func Load(workDir string) (Config, error) {
+	for _, name := range FileNames {
+		b, err := os.ReadFile(filepath.Join(workDir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("could not read config file=%s err=%w", name, err)
+		}
+		c := Default()
+		c.Bumps = nil
+		if err = unmarshal(name, b, &c); err != nil {
+			return Config{}, fmt.Errorf("could not parse config file=%s err=%w", name, err)
+		}
+		if c.Bumps == nil {
+			c.Bumps = Default().Bumps
+		}
+		return c, nil
+	}
+	return Default(), nil
+}
+
+// unmarshal decodes the configuration file of the name, as TOML or YAML by its extension. Unknown settings, ie. a
+// misspelled initialDevelopement, are an error rather than silently ignored.
+func unmarshal(name string, b []byte, c *Config) error {
+	if filepath.Ext(name) == ".toml" {
+		md, err := toml.Decode(string(b), c)
+		if err != nil {
+			return err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("unknown setting %s", undecoded[0])
+		}
+		return nil
+	}
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	d.KnownFields(true)
+	if err := d.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Rules converts the configuration to the rules used by conventional.DetermineNextVersion.
+func (c Config) Rules() (conventional.Rules, error) {
+	bumps := make(map[conventional.CommitType]conventional.Bump)
+	for name, types := range c.Bumps {
+		b, err := conventional.ParseBump(name)
+		if err != nil {
+			return conventional.Rules{}, err
+		}
+		for _, t := range types {
+			bumps[conventional.CommitType(t)] = b
+		}
+	}
+	return conventional.Rules{
+		TagPrefix:          c.TagPrefix,
+		InitialVersion:     c.InitialVersion,
+		Bumps:              bumps,
+		InitialDevelopment: c.InitialDevelopment,
+	}, nil
+}
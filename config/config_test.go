@@ -0,0 +1,173 @@
+package config
+
+import (
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadWithoutFile(t *testing.T) {
+	c, err := Load(t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), c)
+
+	rules, err := c.Rules()
+	assert.NoError(t, err)
+	assert.Equal(t, conventional.DefaultRules(), rules)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	file := `
+tagPrefix: release-
+type: conventional
+initialDevelopment: true
+bumps:
+  minor: [feat]
+  patch: [fix, perf]
+  none: [chore, docs]
+branches:
+  - pattern: release/*
+    maxBump: patch
+`
+	if err := os.WriteFile(filepath.Join(dir, ".semversioner.yaml"), []byte(file), 0644); err != nil {
+		t.Fatalf("could not write config file err=%v", err)
+	}
+
+	c, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Config{
+		TagPrefix:      "release-",
+		InitialVersion: "0.0.0",
+		Type:           "conventional",
+		Bumps: map[string][]string{
+			"minor": {"feat"},
+			"patch": {"fix", "perf"},
+			"none":  {"chore", "docs"},
+		},
+		Branches:           []Branch{{Pattern: "release/*", MaxBump: "patch"}},
+		InitialDevelopment: true,
+	}, c)
+
+	rules, err := c.Rules()
+	assert.NoError(t, err)
+	assert.Equal(t, conventional.Rules{
+		TagPrefix:      "release-",
+		InitialVersion: "0.0.0",
+		Bumps: map[conventional.CommitType]conventional.Bump{
+			conventional.Feature: conventional.BumpMinor,
+			conventional.Fix:     conventional.BumpPatch,
+			"perf":               conventional.BumpPatch,
+			"chore":              conventional.BumpNone,
+			"docs":               conventional.BumpNone,
+		},
+		InitialDevelopment: true,
+	}, rules)
+}
+
+func TestLoadTOML(t *testing.T) {
+	dir := t.TempDir()
+	file := `
+tagPrefix = "release-"
+type = "conventional"
+
+[bumps]
+minor = ["feat"]
+patch = ["fix"]
+
+[[branches]]
+pattern = "develop"
+prerelease = "beta"
+prereleaseCounter = true
+`
+	if err := os.WriteFile(filepath.Join(dir, ".semversioner.toml"), []byte(file), 0644); err != nil {
+		t.Fatalf("could not write config file err=%v", err)
+	}
+
+	c, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Config{
+		TagPrefix:      "release-",
+		InitialVersion: "0.0.0",
+		Type:           "conventional",
+		Bumps:          map[string][]string{"minor": {"feat"}, "patch": {"fix"}},
+		Branches:       []Branch{{Pattern: "develop", Prerelease: "beta", PrereleaseCounter: true}},
+	}, c)
+
+	if err = os.WriteFile(filepath.Join(dir, ".semversioner.toml"), []byte("bumps = [major"), 0644); err != nil {
+		t.Fatalf("could not write config file err=%v", err)
+	}
+	_, err = Load(dir)
+	assert.Error(t, err)
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".semversioner.yml"), []byte("bumps: [major"), 0644); err != nil {
+		t.Fatalf("could not write config file err=%v", err)
+	}
+	_, err := Load(dir)
+	assert.Error(t, err)
+}
+
+func TestLoadUnknownSetting(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".semversioner.yaml": "initialDevelopement: true\n",
+		".semversioner.yml":  "branches:\n  - pattern: main\n    maxbump: patch\n",
+		".semversioner.toml": "initialDevelopement = true\n",
+	}
+	for name, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(file), 0644); err != nil {
+			t.Fatalf("could not write config file err=%v", err)
+		}
+		_, err := Load(dir)
+		assert.Error(t, err, name)
+		if err = os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("could not remove config file err=%v", err)
+		}
+	}
+
+	// an empty file keeps the defaults
+	if err := os.WriteFile(filepath.Join(dir, ".semversioner.yaml"), nil, 0644); err != nil {
+		t.Fatalf("could not write config file err=%v", err)
+	}
+	c, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), c)
+}
+
+func TestRulesUnknownBump(t *testing.T) {
+	c := Config{Bumps: map[string][]string{"huge": {"feat"}}}
+	_, err := c.Rules()
+	assert.Error(t, err)
+}
+
+func TestBranchPolicy(t *testing.T) {
+	c := Config{Branches: []Branch{
+		{Pattern: "main"},
+		{Pattern: "develop", Prerelease: "beta", PrereleaseCounter: true},
+		{Pattern: "feature/*", Type: "snapshot"},
+		{Pattern: "release/*", MaxBump: "patch"},
+	}}
+
+	b, ok, err := c.BranchPolicy("develop")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, c.Branches[1], b)
+
+	b, ok, err = c.BranchPolicy("release/1.x")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, c.Branches[3], b)
+
+	_, ok, err = c.BranchPolicy("hotfix/1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	c.Branches = []Branch{{Pattern: "feature/["}}
+	_, _, err = c.BranchPolicy("feature/x")
+	assert.Error(t, err)
+}
@@ -1,9 +1,7 @@
 package conventional
 
 import (
-	"bufio"
 	"github.com/hooliganlin/versioning/semversioner/git"
-	"log"
 	"regexp"
 	"strings"
 )
@@ -20,6 +18,10 @@ type Commit struct {
 	Title      string
 	Body       string
 	IsBreaking bool
+	// BreakingDescription describes the breaking change for release notes. It is the value of the BREAKING CHANGE
+	// footer, or the Title when the breaking change is only marked by a !
+	BreakingDescription string
+	Footers []Footer
 	git.Commit
 }
 
@@ -32,19 +34,25 @@ func NewCommit(commit git.Commit) Commit {
 	c := ParseCommitSubject(commit.Subject)
 	c.Commit = commit
 
-	scanner := bufio.NewScanner(strings.NewReader(commit.Body))
-	var body []string
-	for scanner.Scan() {
-		body = append(body, scanner.Text())
-	}
-	if err := scanner.Err(); err != nil {
-		log.Printf("[error] reading commit message Body error=%v", err)
-		return Commit{}
+	// lines of any length, without the carriage return of CRLF line endings
+	body := strings.Split(strings.TrimSuffix(commit.Body, "\n"), "\n")
+	for i, line := range body {
+		body[i] = strings.TrimSuffix(line, "\r")
 	}
 	c.Body = strings.TrimPrefix(strings.Join(body, "\n"), "\n")
 
-	// from the Body check if it's breaking
-	c.IsBreaking = c.IsBreaking || strings.Contains(c.Body, "BREAKING CHANGE")
+	// from the footers check if it's breaking
+	c.Footers = ParseFooters(c.Body)
+	for _, f := range c.Footers {
+		if f.IsBreakingChange() {
+			c.IsBreaking = true
+			c.BreakingDescription = f.Value
+			break
+		}
+	}
+	if c.IsBreaking && c.BreakingDescription == "" {
+		c.BreakingDescription = c.Title
+	}
 
 	return c
 }
@@ -77,7 +85,7 @@ func parseCommitType(c string) Commit {
 	//extract the Scope if exists based on https://www.conventionalcommits.org/en/v1.0.0/#summary
 	re := regexp.MustCompile(`([a-zA-Z].*)\((.*?)\)`)
 	results := re.FindStringSubmatch(c)
-	isBreaking := strings.Contains(c[len(c)-1:], "!")
+	isBreaking := strings.HasSuffix(c, "!")
 	if len(results) > 0 {
 		commitType := results[1]
 		var scope string
@@ -3,6 +3,7 @@ package conventional
 import (
 	"github.com/hooliganlin/versioning/semversioner/git"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,6 +24,7 @@ func TestNewCommit(t *testing.T) {
 		Title:      "something happened and this should be fixed",
 		Body:       "I found this bug and fixed it\n\nSigned-of-by: megatron",
 		IsBreaking: false,
+		Footers:    []Footer{{Token: "Signed-of-by", Value: "megatron"}},
 		Commit:     commit,
 	}, c)
 
@@ -49,6 +51,13 @@ func TestNewCommit(t *testing.T) {
 	assert.Equal(t, Commit{}, c)
 }
 
+func TestNewCommitLongBody(t *testing.T) {
+	long := strings.Repeat("x", 100000)
+	c := NewCommit(git.Commit{Subject: "fix: long line", Body: long + "\r\n\r\nBREAKING CHANGE: drop it\r\n"})
+	assert.Equal(t, long+"\n\nBREAKING CHANGE: drop it", c.Body)
+	assert.True(t, c.IsBreaking)
+}
+
 func TestParseCommitTitle(t *testing.T) {
 	commitWithScope := "chore(logging): First commitTitle"
 	commitWithNoScope := "feat: First feature"
@@ -108,6 +117,9 @@ func TestParseCommitType(t *testing.T) {
 	misplacedBreaking := "feat!(something)"
 	c = parseCommitType(misplacedBreaking)
 	assert.Equal(t, Commit {Type: "feat!", IsBreaking: false, Scope: "something"}, c)
+
+	c = parseCommitType("")
+	assert.Equal(t, Commit {}, c)
 }
 
 func TestParseBodyBreakingChange(t *testing.T) {
@@ -120,4 +132,5 @@ func TestParseBodyBreakingChange(t *testing.T) {
 	}
 	c := NewCommit(commit)
 	assert.True(t, c.IsBreaking)
+	assert.Equal(t, "This requires a circle", c.BreakingDescription)
 }
\ No newline at end of file
@@ -0,0 +1,112 @@
+package conventional
+
+import (
+	"fmt"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"strings"
+)
+
+// CommitExplanation is the rule a single commit triggered.
+type CommitExplanation struct {
+	Commit Commit
+	Bump   Bump
+	// Rule describes the rule applied to the commit, ie. "feat => minor"
+	Rule string
+	// Ignored is true for commits without a rule or which are not conventional.
+	Ignored bool
+	// Decisive is true for the commit which decided the final bump.
+	Decisive bool
+}
+
+// Explanation describes how the commits since the latest tag lead to the bump of the next version.
+type Explanation struct {
+	Bump    Bump
+	Commits []CommitExplanation
+	// Override is the Release-As or Semver-Bump trailer overriding the rules, nil when the rules decide.
+	Override *Override
+	// Reason describes how the rules rather than the commits decided the Bump, ie. for the first release or a bump
+	// lowered in the InitialDevelopment, empty otherwise.
+	Reason string
+}
+
+// Explain evaluates every commit since the latestTag against the rules the same way DetermineNextVersion does and
+// records which rule each commit triggered and which commit was decisive. Commits reverted within the range are
+// ignored. Before the first tag, ie. an empty latestTag, only a Release-As trailer is decisive, the first release is a
+// patch of the initial version otherwise. The Bump is the one applied to the latestTag, see Rules.VersionBump.
+func Explain(commits []Commit, rules Rules, latestTag string) Explanation {
+	revertedBy, reverting := revertPairs(commits)
+	bump := DetermineBump(DropReverted(commits), rules)
+	override, overridden := FindOverride(DropReverted(commits), rules)
+	if latestTag == "" {
+		override, overridden = FindReleaseAs(DropReverted(commits))
+	}
+	releaseAs := overridden && override.Token == ReleaseAsToken
+	// like DecisiveCommit, the first commit triggering the final bump decides it unless a Release-As trailer does
+	decided := bump == BumpNone || releaseAs
+
+	e := Explanation{Bump: bump, Commits: make([]CommitExplanation, 0, len(commits))}
+	switch v, err := rules.ParseTag(latestTag); {
+	case releaseAs:
+		// the trailer names the version, whatever the bump
+	case latestTag == "":
+		e.Bump, e.Reason = BumpPatch, fmt.Sprintf("first release after the initial version %s", rules.InitialVersion)
+		decided = true
+	case err == nil && rules.VersionBump(*v, bump) != bump:
+		// the commits still decide, their bump is lowered like DetermineNextVersion does
+		e.Bump = rules.VersionBump(*v, bump)
+		e.Reason = fmt.Sprintf("lowered to a %s while the major is 0", e.Bump)
+	}
+	if overridden {
+		e.Override = &override
+	}
+	for _, c := range commits {
+		b, ok := rules.BumpFor(c)
+		ce := CommitExplanation{Commit: c, Bump: b}
+		revert, reverted := revertedBy[c.Hash]
+		target, reverts := reverting[c.Hash]
+		switch {
+		case reverted:
+			ce.Rule = fmt.Sprintf("reverted by %s", shortHash(revert))
+			ce.Bump, ce.Ignored = BumpNone, true
+		case reverts:
+			ce.Rule = fmt.Sprintf("reverts %s", shortHash(target))
+			ce.Bump, ce.Ignored = BumpNone, true
+		case releaseAs && c.Hash == override.Commit.Hash:
+			ce.Rule = fmt.Sprintf("%s: %s", override.Token, override.Value)
+			ce.Decisive = true
+		case hasBumpOverride(c):
+			ce.Rule = fmt.Sprintf("%s => %s", SemverBumpToken, b)
+		case c.IsBreaking:
+			ce.Rule = fmt.Sprintf("breaking change => %s", b)
+		case !c.IsConventional():
+			ce.Rule = "not a conventional commit"
+			ce.Ignored = true
+		case !ok:
+			ce.Rule = fmt.Sprintf("no rule for %s", c.Type)
+			ce.Ignored = true
+		default:
+			ce.Rule = fmt.Sprintf("%s => %s", c.Type, b)
+		}
+		if !decided && !ce.Ignored && ce.Bump == bump {
+			ce.Decisive = true
+			decided = true
+		}
+		e.Commits = append(e.Commits, ce)
+	}
+	return e
+}
+
+// IsConventional reports whether the subject of the commit follows the conventional commit format, ie. it has a type
+// without any whitespace.
+func (c Commit) IsConventional() bool {
+	return c.Type != "" && !strings.ContainsAny(string(c.Type), " \t")
+}
+
+func hasBumpOverride(c Commit) bool {
+	_, ok := c.BumpOverride()
+	return ok
+}
+
+func shortHash(hash string) string {
+	return git.Commit{Hash: hash}.ShortHash()
+}
@@ -0,0 +1,110 @@
+package conventional
+
+import (
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	commits := ParseCommits([]git.Commit{
+		{Subject: "fix: fix 1", Hash: "a"},
+		{Subject: "feat(api): feature 1", Hash: "b"},
+		{Subject: "feat: feature 2", Hash: "c"},
+		{Subject: "chore: chore 1", Hash: "d"},
+		{Subject: "Merge branch 'main': into feature", Hash: "e"},
+		{Subject: "This is not a conventional commit", Hash: "f"},
+	})
+	rules := DefaultRules()
+
+	e := Explain(commits, rules, "v1.0.0")
+	assert.Equal(t, BumpMinor, e.Bump)
+	assert.Equal(t, []CommitExplanation{
+		{Commit: commits[0], Bump: BumpPatch, Rule: "fix => patch"},
+		{Commit: commits[1], Bump: BumpMinor, Rule: "feat => minor", Decisive: true},
+		{Commit: commits[2], Bump: BumpMinor, Rule: "feat => minor"},
+		{Commit: commits[3], Bump: BumpNone, Rule: "no rule for chore", Ignored: true},
+		{Commit: commits[4], Bump: BumpNone, Rule: "not a conventional commit", Ignored: true},
+		{Commit: commits[5], Bump: BumpNone, Rule: "not a conventional commit", Ignored: true},
+	}, e.Commits)
+
+	breaking := NewCommit(git.Commit{Subject: "chore!: drop go 1.16", Hash: "g"})
+	e = Explain(append(commits, breaking), rules, "v1.0.0")
+	assert.Equal(t, BumpMajor, e.Bump)
+	assert.Equal(t, CommitExplanation{Commit: breaking, Bump: BumpMajor, Rule: "breaking change => major", Decisive: true},
+		e.Commits[6])
+
+	e = Explain(commits[3:], rules, "v1.0.0")
+	assert.Equal(t, BumpNone, e.Bump)
+	for _, c := range e.Commits {
+		assert.False(t, c.Decisive)
+	}
+}
+
+func TestExplainOverrides(t *testing.T) {
+	commits := ParseCommits([]git.Commit{
+		{Subject: "fix: fix 1", Body: "Semver-Bump: minor", Hash: "a"},
+		{Subject: "feat: feature 1", Hash: "b"},
+	})
+	rules := DefaultRules()
+
+	e := Explain(commits, rules, "v1.0.0")
+	assert.Equal(t, BumpMinor, e.Bump)
+	assert.Equal(t, &Override{Token: SemverBumpToken, Value: "minor", Commit: commits[0]}, e.Override)
+	assert.Equal(t, CommitExplanation{Commit: commits[0], Bump: BumpMinor, Rule: "Semver-Bump => minor", Decisive: true},
+		e.Commits[0])
+
+	release := NewCommit(git.Commit{Subject: "chore: release", Body: "Release-As: 2.0.0", Hash: "c"})
+	e = Explain(append(commits, release), rules, "v1.0.0")
+	assert.Equal(t, &Override{Token: ReleaseAsToken, Value: "2.0.0", Commit: release}, e.Override)
+	assert.False(t, e.Commits[0].Decisive)
+	assert.Equal(t, CommitExplanation{Commit: release, Bump: BumpNone, Rule: "Release-As: 2.0.0", Decisive: true},
+		e.Commits[2])
+}
+
+func TestExplainFirstRelease(t *testing.T) {
+	commits := ParseCommits([]git.Commit{
+		{Subject: "feat!: breaking", Hash: "a"},
+		{Subject: "fix: fix 1", Body: "Semver-Bump: minor", Hash: "b"},
+	})
+	rules := DefaultRules()
+
+	e := Explain(commits, rules, "")
+	assert.Equal(t, BumpPatch, e.Bump)
+	assert.Equal(t, "first release after the initial version 0.0.0", e.Reason)
+	assert.Nil(t, e.Override)
+	for _, c := range e.Commits {
+		assert.False(t, c.Decisive)
+	}
+
+	release := NewCommit(git.Commit{Subject: "chore: release", Body: "Release-As: 0.1.0", Hash: "c"})
+	e = Explain(append(commits, release), rules, "")
+	assert.Equal(t, "", e.Reason)
+	assert.Equal(t, &Override{Token: ReleaseAsToken, Value: "0.1.0", Commit: release}, e.Override)
+	assert.True(t, e.Commits[2].Decisive)
+}
+
+func TestExplainInitialDevelopment(t *testing.T) {
+	commits := ParseCommits([]git.Commit{
+		{Subject: "fix: fix 1", Hash: "a"},
+		{Subject: "feat!: breaking", Hash: "b"},
+	})
+	rules := DefaultRules()
+	rules.InitialDevelopment = true
+
+	e := Explain(commits, rules, "v0.4.2")
+	assert.Equal(t, BumpMinor, e.Bump)
+	assert.Equal(t, "lowered to a minor while the major is 0", e.Reason)
+	assert.Equal(t, CommitExplanation{Commit: commits[1], Bump: BumpMajor, Rule: "breaking change => major", Decisive: true},
+		e.Commits[1])
+
+	e = Explain(commits, rules, "v1.4.2")
+	assert.Equal(t, BumpMajor, e.Bump)
+	assert.Equal(t, "", e.Reason)
+}
+
+func TestIsConventional(t *testing.T) {
+	assert.True(t, ParseCommitSubject("feat(api)!: breaking").IsConventional())
+	assert.False(t, ParseCommitSubject("this is my first commit").IsConventional())
+	assert.False(t, ParseCommitSubject("Merge branch 'main': into feature").IsConventional())
+}
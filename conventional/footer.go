@@ -0,0 +1,66 @@
+package conventional
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Breaking change footer tokens of the conventional commit specification.
+const (
+	BreakingChangeToken       = "BREAKING CHANGE"
+	BreakingChangeHyphenToken = "BREAKING-CHANGE"
+)
+
+// Footer is a git trailer style token/value pair at the end of a commit message, ie.
+//	Reviewed-by: Z
+//	Refs #133
+type Footer struct {
+	Token string
+	Value string
+}
+
+// footerLine matches the first line of a footer. Tokens use - instead of whitespace, except for BREAKING CHANGE, and
+// are separated from the value by ": " or " #".
+var footerLine = regexp.MustCompile(`^(BREAKING CHANGE|[A-Za-z0-9][A-Za-z0-9-]*)(: | #)(.*)$`)
+
+// IsBreakingChange reports whether the footer announces a breaking change.
+func (f Footer) IsBreakingChange() bool {
+	return f.Token == BreakingChangeToken || f.Token == BreakingChangeHyphenToken
+}
+
+// ParseFooters parses the footers of a commit body. Footers are the trailing paragraphs of the body starting with a
+// footer token. A value continues on the following lines until the next footer token.
+func ParseFooters(body string) []Footer {
+	lines := strings.Split(strings.TrimRight(body, "\n"), "\n")
+
+	// walk the paragraphs backwards while they start with a footer token
+	start := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		paragraphStart := i == 0 || strings.TrimSpace(lines[i-1]) == ""
+		if !paragraphStart || strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if !footerLine.MatchString(lines[i]) {
+			break
+		}
+		start = i
+	}
+
+	var footers []Footer
+	for _, line := range lines[start:] {
+		if m := footerLine.FindStringSubmatch(line); m != nil {
+			value := m[3]
+			if m[2] == " #" {
+				value = "#" + value
+			}
+			footers = append(footers, Footer{Token: m[1], Value: value})
+			continue
+		}
+		last := len(footers) - 1
+		footers[last].Value += "\n" + line
+	}
+	for i := range footers {
+		footers[i].Value = strings.TrimRight(footers[i].Value, "\n ")
+	}
+	return footers
+}
@@ -0,0 +1,47 @@
+package conventional
+
+import (
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseFooters(t *testing.T) {
+	body := "This is the body.\n\nIt talks about a BREAKING CHANGE in prose.\n\n" +
+		"Reviewed-by: Z\nRefs #133\nBREAKING CHANGE: the config file\n  moved to .semversioner.yaml\n\n" +
+		"Signed-off-by: megatron"
+	assert.Equal(t, []Footer{
+		{Token: "Reviewed-by", Value: "Z"},
+		{Token: "Refs", Value: "#133"},
+		{Token: "BREAKING CHANGE", Value: "the config file\n  moved to .semversioner.yaml"},
+		{Token: "Signed-off-by", Value: "megatron"},
+	}, ParseFooters(body))
+
+	assert.Equal(t, []Footer{{Token: "BREAKING-CHANGE", Value: "drops go 1.16"}},
+		ParseFooters("BREAKING-CHANGE: drops go 1.16\n"))
+
+	assert.Empty(t, ParseFooters(""))
+	assert.Empty(t, ParseFooters("Reviewed-by: Z\n\nthis prose paragraph comes last"))
+	assert.Empty(t, ParseFooters("Not a footer because of spaces: in the token"))
+}
+
+func TestBreakingChangeFooter(t *testing.T) {
+	commit := git.Commit{
+		Subject: "feat: square peg is now a circle",
+		Body:    "This mentions a BREAKING CHANGE in prose but is not breaking.",
+	}
+	c := NewCommit(commit)
+	assert.False(t, c.IsBreaking)
+	assert.Empty(t, c.BreakingDescription)
+
+	commit.Body = "Some body\n\nBREAKING-CHANGE: square holes are gone"
+	c = NewCommit(commit)
+	assert.True(t, c.IsBreaking)
+	assert.Equal(t, "square holes are gone", c.BreakingDescription)
+
+	commit.Subject = "feat!: square peg is now a circle"
+	commit.Body = ""
+	c = NewCommit(commit)
+	assert.True(t, c.IsBreaking)
+	assert.Equal(t, "square peg is now a circle", c.BreakingDescription)
+}
@@ -0,0 +1,129 @@
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ViolationKind identifies the rule of the conventional commit specification a message breaks.
+type ViolationKind string
+
+const (
+	MissingType       ViolationKind = "missing-type"
+	UnknownType       ViolationKind = "unknown-type"
+	EmptyDescription  ViolationKind = "empty-description"
+	MalformedScope    ViolationKind = "malformed-scope"
+	MisplacedBreaking ViolationKind = "misplaced-breaking"
+	MissingSpace      ViolationKind = "missing-space"
+)
+
+// KnownTypes are the commit types of the conventional commit (Angular) convention.
+var KnownTypes = []CommitType{
+	"build", "chore", "ci", "docs", Feature, Fix, "perf", "refactor", "revert", "style", "test",
+}
+
+// Violation is a rule of the conventional commit specification broken by a commit message.
+type Violation struct {
+	Kind    ViolationKind
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Kind, v.Message)
+}
+
+// gitGenerated matches the subjects git generates, ie. Merge branch 'main' or Revert "feat: add the thing"
+var gitGenerated = regexp.MustCompile(`^(Merge |Revert "|(fixup|squash|amend)! )`)
+
+// typeName matches the name of the type in front of the scope or !
+var typeName = regexp.MustCompile(`^[a-zA-Z]+`)
+
+// strictCommitType matches a well-formed commit type such as feat, feat(scope), feat! or feat(scope)!
+var strictCommitType = regexp.MustCompile(`^[a-zA-Z]+(\([^()\s]+\))?!?$`)
+
+// Lint validates a commit message against the conventional commit specification and returns every rule it breaks.
+// Lines starting with # are comments, as in the message file of a commit-msg hook. Types must be one of types.
+// Messages git generates for merges, reverts and fixups are not linted.
+func Lint(message string, types []CommitType) []Violation {
+	subject := firstLine(message)
+	if gitGenerated.MatchString(subject) {
+		return nil
+	}
+	if !strings.Contains(subject, ":") {
+		return []Violation{{
+			Kind:    MissingType,
+			Message: fmt.Sprintf("subject %q must start with a type, ie. feat: add the thing", subject),
+		}}
+	}
+
+	var violations []Violation
+	rawType := subject[:strings.Index(subject, ":")]
+	name := typeName.FindString(rawType)
+	rest := strings.TrimPrefix(rawType, name)
+	switch {
+	case name == "" || (rest != "" && rest[0] != '(' && rest[0] != '!'):
+		violations = append(violations, Violation{
+			Kind:    MissingType,
+			Message: fmt.Sprintf("%q in front of the colon is not a type, ie. feat: add the thing", rawType),
+		})
+	case strings.Contains(strings.TrimSuffix(rest, "!"), "!"):
+		violations = append(violations, Violation{
+			Kind:    MisplacedBreaking,
+			Message: fmt.Sprintf("%q must have a single ! right before the colon, ie. feat(scope)!:", rawType),
+		})
+	case !strictCommitType.MatchString(rawType):
+		violations = append(violations, Violation{
+			Kind:    MalformedScope,
+			Message: fmt.Sprintf("%q must be a type with an optional scope in parentheses, ie. feat(scope)", rawType),
+		})
+	case !isKnownType(CommitType(name), types):
+		violations = append(violations, Violation{
+			Kind:    UnknownType,
+			Message: fmt.Sprintf("type %q is not one of %s", name, joinTypes(types)),
+		})
+	}
+
+	c := ParseCommitSubject(subject)
+	description := subject[strings.Index(subject, ":")+1:]
+	switch {
+	case strings.TrimSpace(c.Title) == "":
+		violations = append(violations, Violation{Kind: EmptyDescription, Message: "the description after the colon is empty"})
+	case !strings.HasPrefix(description, " "):
+		violations = append(violations, Violation{
+			Kind:    MissingSpace,
+			Message: fmt.Sprintf("the colon must be followed by a space, ie. %s: %s", rawType, strings.TrimSpace(description)),
+		})
+	}
+	return violations
+}
+
+// firstLine returns the first line of the message which is not a comment.
+func firstLine(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		return strings.TrimRight(line, "\r")
+	}
+	return ""
+}
+
+func isKnownType(t CommitType, types []CommitType) bool {
+	for _, known := range types {
+		if strings.EqualFold(string(known), string(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinTypes(types []CommitType) string {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, string(t))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
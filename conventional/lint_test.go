@@ -0,0 +1,56 @@
+package conventional
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	valid := []string{
+		"feat: add the thing",
+		"fix(api): close the connection",
+		"feat(api)!: remove v1",
+		"chore!: drop go 1.16",
+		"FEAT: shouting is allowed",
+		"# Please enter the commit message\n\nfeat: add the thing\n\nbody\n# comment",
+		"Merge branch 'main' into feature",
+		"Revert \"feat: add the thing\"",
+		"fixup! feat: add the thing",
+	}
+	for _, m := range valid {
+		assert.Empty(t, Lint(m, KnownTypes), m)
+	}
+
+	invalid := map[string][]ViolationKind{
+		"this is my first commit":      {MissingType},
+		"":                             {MissingType},
+		": no type":                    {MissingType},
+		"Merged main: into feature":    {MissingType},
+		"feta: add the thing":          {UnknownType},
+		"feat:":                        {EmptyDescription},
+		"feat(api):   ":                {EmptyDescription},
+		"feat(api: add the thing":      {MalformedScope},
+		"feat(): add the thing":        {MalformedScope},
+		"feat(api)(db): add the thing": {MalformedScope},
+		"feat(api)x: add the thing":    {MalformedScope},
+		"feat!(api): add the thing":    {MisplacedBreaking},
+		"feat!!: add the thing":        {MisplacedBreaking},
+		"feta!!:":                      {MisplacedBreaking, EmptyDescription},
+		"feat:add the thing":           {MissingSpace},
+		"feta(api):add the thing":      {UnknownType, MissingSpace},
+	}
+	for m, kinds := range invalid {
+		violations := Lint(m, KnownTypes)
+		var got []ViolationKind
+		for _, v := range violations {
+			assert.NotEmpty(t, v.Message)
+			got = append(got, v.Kind)
+		}
+		assert.Equal(t, kinds, got, m)
+	}
+
+	assert.Equal(t, "missing-space: the colon must be followed by a space, ie. feat(api): add the thing",
+		Lint("feat(api):add the thing", KnownTypes)[0].String())
+	assert.Empty(t, Lint("deps: bump semver", append(KnownTypes, "deps")))
+	assert.Equal(t, "unknown-type: type \"deps\" is not one of fix", Lint("deps: bump semver", []CommitType{Fix})[0].String())
+}
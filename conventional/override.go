@@ -0,0 +1,100 @@
+package conventional
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver"
+	"strings"
+)
+
+// Override trailer tokens, they declare the next version or the bump of a commit instead of its type, ie.
+//	Release-As: 2.0.0
+//	Semver-Bump: minor
+const (
+	ReleaseAsToken  = "Release-As"
+	SemverBumpToken = "Semver-Bump"
+)
+
+// Override is a Release-As or Semver-Bump trailer of a commit overriding the versioning rules.
+type Override struct {
+	Token  string
+	Value  string
+	Commit Commit
+}
+
+func (o Override) String() string {
+	return fmt.Sprintf("%s: %s in commit %s", o.Token, o.Value, o.Commit.ShortHash())
+}
+
+// Version parses the version of a Release-As override.
+func (o Override) Version() (*semver.Version, error) {
+	v, err := semver.NewVersion(o.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s err=%w", o, err)
+	}
+	return v, nil
+}
+
+// override returns the first footer of the commit with the token, which is matched case-insensitively like git
+// trailers are.
+func (c Commit) override(token string) (Override, bool) {
+	for _, f := range c.Footers {
+		if strings.EqualFold(f.Token, token) {
+			return Override{Token: token, Value: strings.TrimSpace(f.Value), Commit: c}, true
+		}
+	}
+	return Override{}, false
+}
+
+// BumpOverride returns the bump of the Semver-Bump trailer of the commit. The second return value is false when the
+// commit has none or its value is not a bump.
+func (c Commit) BumpOverride() (Bump, bool) {
+	o, ok := c.override(SemverBumpToken)
+	if !ok {
+		return BumpNone, false
+	}
+	b, err := ParseBump(strings.ToLower(o.Value))
+	return b, err == nil
+}
+
+// FindReleaseAs returns the Release-As override of the newest commit carrying one.
+func FindReleaseAs(commits []Commit) (Override, bool) {
+	for _, c := range commits {
+		if o, ok := c.override(ReleaseAsToken); ok {
+			return o, true
+		}
+	}
+	return Override{}, false
+}
+
+// FindOverride returns the override deciding the next version: the newest Release-As trailer, otherwise the
+// Semver-Bump trailer of the commit deciding the bump. The second return value is false when the rules decide.
+func FindOverride(commits []Commit, rules Rules) (Override, bool) {
+	if o, ok := FindReleaseAs(commits); ok {
+		return o, true
+	}
+	c, ok := DecisiveCommit(commits, rules, DetermineBump(commits, rules))
+	if !ok {
+		return Override{}, false
+	}
+	if _, ok = c.BumpOverride(); !ok {
+		return Override{}, false
+	}
+	return c.override(SemverBumpToken)
+}
+
+// checkOverrides returns an error for the first override trailer of the commits with an invalid value.
+func checkOverrides(commits []Commit) error {
+	for _, c := range commits {
+		if o, ok := c.override(SemverBumpToken); ok {
+			if _, valid := c.BumpOverride(); !valid {
+				return fmt.Errorf("invalid %s, must be one of major, minor, patch or none", o)
+			}
+		}
+		if o, ok := c.override(ReleaseAsToken); ok {
+			if _, err := o.Version(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package conventional
+
+import (
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBumpOverride(t *testing.T) {
+	b, ok := NewCommit(git.Commit{Subject: "fix: fix 1", Body: "Semver-Bump: major"}).BumpOverride()
+	assert.True(t, ok)
+	assert.Equal(t, BumpMajor, b)
+
+	b, ok = NewCommit(git.Commit{Subject: "feat!: feature 1", Body: "semver-bump: None"}).BumpOverride()
+	assert.True(t, ok)
+	assert.Equal(t, BumpNone, b)
+
+	_, ok = NewCommit(git.Commit{Subject: "fix: fix 1", Body: "Semver-Bump: huge"}).BumpOverride()
+	assert.False(t, ok)
+
+	_, ok = NewCommit(git.Commit{Subject: "fix: fix 1", Body: "Mentions Semver-Bump: major in the body.\n\nRefs: #1"}).BumpOverride()
+	assert.False(t, ok)
+}
+
+func TestFindOverride(t *testing.T) {
+	rules := DefaultRules()
+	commits := ParseCommits([]git.Commit{
+		{Subject: "fix: fix 2", Hash: "aaaaaaaaaa"},
+		{Subject: "docs: docs 1", Body: "Semver-Bump: minor", Hash: "bbbbbbbbbb"},
+		{Subject: "fix: fix 1", Hash: "cccccccccc"},
+	})
+
+	o, ok := FindOverride(commits, rules)
+	assert.True(t, ok)
+	assert.Equal(t, "Semver-Bump: minor in commit bbbbbbb", o.String())
+
+	_, ok = FindOverride(commits[2:], rules)
+	assert.False(t, ok)
+
+	commits = append(commits, NewCommit(git.Commit{Subject: "chore: release", Body: "Release-As: 2.0.0", Hash: "dddddddddd"}))
+	o, ok = FindOverride(commits, rules)
+	assert.True(t, ok)
+	assert.Equal(t, "Release-As: 2.0.0 in commit ddddddd", o.String())
+	v, err := o.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0", v.String())
+}
+
+func TestCheckOverrides(t *testing.T) {
+	assert.NoError(t, checkOverrides(ParseCommits([]git.Commit{
+		{Subject: "fix: fix 1", Body: "Semver-Bump: patch"},
+		{Subject: "chore: release", Body: "Release-As: v2.0.0-rc.1"},
+	})))
+	assert.EqualError(t, checkOverrides(ParseCommits([]git.Commit{{Subject: "fix: fix 1", Body: "Semver-Bump: huge", Hash: "abc"}})),
+		"invalid Semver-Bump: huge in commit abc, must be one of major, minor, patch or none")
+	assert.Error(t, checkOverrides(ParseCommits([]git.Commit{{Subject: "chore: release", Body: "Release-As: next"}})))
+}
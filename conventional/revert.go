@@ -0,0 +1,116 @@
+package conventional
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Revert is the commit type of the conventional commit convention for reverts.
+const Revert CommitType = "revert"
+
+var (
+	// revertedHash matches the line git adds to the body of a revert commit.
+	revertedHash = regexp.MustCompile(`This reverts commit ([0-9a-fA-F]{7,40})`)
+	// revertSubject matches the subject git generates for a revert commit, ie. Revert "feat: add the thing"
+	revertSubject = regexp.MustCompile(`^Revert "(.*)"$`)
+)
+
+// IsRevert reports whether the commit reverts another one, either generated by git revert or with the revert type.
+func (c Commit) IsRevert() bool {
+	return c.Type == Revert || revertSubject.MatchString(c.Subject)
+}
+
+// revertTarget returns the hash and subject of the commit c reverts. The hash is read from the
+// "This reverts commit <hash>" line of the body or a Refs footer, the subject from the subject of c.
+func (c Commit) revertTarget() (string, string) {
+	var hash string
+	if m := revertedHash.FindStringSubmatch(c.Body); m != nil {
+		hash = strings.ToLower(m[1])
+	}
+	for _, f := range c.Footers {
+		if hash == "" && f.Token == "Refs" {
+			hash = strings.ToLower(strings.TrimSpace(f.Value))
+		}
+	}
+
+	subject := c.Title
+	if m := revertSubject.FindStringSubmatch(c.Subject); m != nil {
+		subject = m[1]
+	}
+	return hash, subject
+}
+
+// reverts reports whether c reverts the target commit.
+func (c Commit) reverts(target Commit) bool {
+	hash, subject := c.revertTarget()
+	if hash != "" {
+		return strings.HasPrefix(target.Hash, hash)
+	}
+	return subject != "" && subject == target.Subject
+}
+
+// revertPairs pairs the reverts with the commits of the same range they revert. Both maps are keyed by hash:
+// revertedBy maps a reverted commit to its revert and reverting maps a revert to the commit it reverts. A reverted
+// revert restores the original commit. The commits are ordered newest first, as git log lists them.
+func revertPairs(commits []Commit) (revertedBy map[string]string, reverting map[string]string) {
+	revertedBy = make(map[string]string)
+	reverting = make(map[string]string)
+	var w revertWalk
+	for _, c := range commits {
+		if revert, ok := w.next(c); ok {
+			revertedBy[c.Hash] = revert.Hash
+			reverting[revert.Hash] = c.Hash
+		}
+	}
+	return revertedBy, reverting
+}
+
+// revertWalk pairs the reverts with the commits they revert while walking a range newest first, as git log lists it.
+// A revert comes before the commit it reverts, so only the reverts still waiting for theirs are held.
+type revertWalk struct {
+	// pending are the reverts which haven't met the commit they revert yet, with their index in the range.
+	pending []pendingRevert
+	walked  int
+}
+
+type pendingRevert struct {
+	Commit
+	index int
+}
+
+// next walks the commit c and returns the newer revert cancelling it out, the second return value is false when none
+// does. A revert which is not cancelled out waits for the commit it reverts, reverting a revert restores the original
+// commit.
+func (w *revertWalk) next(c Commit) (Commit, bool) {
+	index := w.walked
+	w.walked++
+	// the revert closest to c wins
+	for i := len(w.pending) - 1; i >= 0; i-- {
+		if revert := w.pending[i]; revert.reverts(c) {
+			w.pending = append(w.pending[:i], w.pending[i+1:]...)
+			return revert.Commit, true
+		}
+	}
+	if c.IsRevert() {
+		w.pending = append(w.pending, pendingRevert{Commit: c, index: index})
+	}
+	return Commit{}, false
+}
+
+// DropReverted returns the commits without the ones reverted within the range and without the reverts cancelling
+// them out. Reverts of commits outside the range are kept.
+func DropReverted(commits []Commit) []Commit {
+	revertedBy, reverting := revertPairs(commits)
+	if len(reverting) == 0 {
+		return commits
+	}
+	kept := make([]Commit, 0, len(commits))
+	for _, c := range commits {
+		_, reverted := revertedBy[c.Hash]
+		_, revert := reverting[c.Hash]
+		if !reverted && !revert {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
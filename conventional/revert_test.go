@@ -0,0 +1,48 @@
+package conventional
+
+import (
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDropReverted(t *testing.T) {
+	// commits are listed newest first like git log does
+	commits := ParseCommits([]git.Commit{
+		{Subject: "Revert \"fix: fix 1\"", Body: "This reverts commit 0000000000000000000000000000000000000001.", Hash: "6"},
+		{Subject: "revert: feat: feature 2", Hash: "5"},
+		{Subject: "chore: chore 1", Hash: "4"},
+		{Subject: "feat: feature 2", Hash: "3"},
+		{Subject: "Revert \"feat: feature 1\"", Body: "This reverts commit aaaaaaa.", Hash: "2"},
+		{Subject: "feat: feature 1", Hash: "aaaaaaaaaaaa"},
+	})
+
+	assert.Equal(t, []Commit{commits[0], commits[2]}, DropReverted(commits))
+	assert.True(t, commits[0].IsRevert())
+	assert.True(t, commits[1].IsRevert())
+	assert.False(t, commits[2].IsRevert())
+}
+
+func TestDropRevertedRevert(t *testing.T) {
+	commits := ParseCommits([]git.Commit{
+		{Subject: "Revert \"Revert \"feat: feature 1\"\"", Body: "This reverts commit bbbbbbbbbb.", Hash: "cccccccccc"},
+		{Subject: "Revert \"feat: feature 1\"", Body: "This reverts commit aaaaaaaaaa.", Hash: "bbbbbbbbbb"},
+		{Subject: "feat: feature 1", Hash: "aaaaaaaaaa"},
+	})
+	assert.Equal(t, []Commit{commits[2]}, DropReverted(commits))
+
+	e := Explain(commits, DefaultRules(), "v1.0.0")
+	assert.Equal(t, BumpMinor, e.Bump)
+	assert.Equal(t, "reverts bbbbbbb", e.Commits[0].Rule)
+	assert.Equal(t, "reverted by ccccccc", e.Commits[1].Rule)
+	assert.True(t, e.Commits[2].Decisive)
+}
+
+func TestDropRevertedOutsideRange(t *testing.T) {
+	commits := ParseCommits([]git.Commit{
+		{Subject: "Revert \"feat: feature 1\"", Body: "This reverts commit aaaaaaaaaa.", Hash: "bbbbbbbbbb"},
+		{Subject: "feat: feature 2", Hash: "dddddddddd"},
+	})
+	assert.Equal(t, commits, DropReverted(commits))
+	assert.Equal(t, BumpMinor, DetermineBump(DropReverted(commits), DefaultRules()))
+}
@@ -0,0 +1,172 @@
+package conventional
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"strings"
+)
+
+// ErrBumpNotAllowed is returned when a version is bumped higher than Rules.MaxBump allows.
+var ErrBumpNotAllowed = errors.New("bump not allowed")
+
+// ErrInvalidTag is returned for a tag which is not the TagPrefix followed by a semantic version.
+type ErrInvalidTag struct {
+	Tag string
+}
+
+func (e ErrInvalidTag) Error() string {
+	return fmt.Sprintf("invalid tag=%s, it must be the tag prefix followed by a semantic version", e.Tag)
+}
+
+// Bump is the part of a semantic version a commit increments.
+type Bump int
+
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+var bumpNames = map[Bump]string{
+	BumpNone:  "none",
+	BumpPatch: "patch",
+	BumpMinor: "minor",
+	BumpMajor: "major",
+}
+
+func (b Bump) String() string {
+	return bumpNames[b]
+}
+
+// Apply increments the part of v for the bump. BumpNone returns v unchanged.
+func (b Bump) Apply(v semver.Version) semver.Version {
+	switch b {
+	case BumpMajor:
+		return v.IncMajor()
+	case BumpMinor:
+		return v.IncMinor()
+	case BumpPatch:
+		return v.IncPatch()
+	default:
+		return v
+	}
+}
+
+// BumpBetween returns the highest part of the version which differs from v to next, ie. minor from 1.2.3 to 1.4.0.
+// BumpNone is returned when only the pre-release or the build metadata differ.
+func BumpBetween(v semver.Version, next semver.Version) Bump {
+	switch {
+	case next.Major() != v.Major():
+		return BumpMajor
+	case next.Minor() != v.Minor():
+		return BumpMinor
+	case next.Patch() != v.Patch():
+		return BumpPatch
+	default:
+		return BumpNone
+	}
+}
+
+// ParseBump converts the name of a bump (none, patch, minor or major) to a Bump.
+func ParseBump(s string) (Bump, error) {
+	for b, name := range bumpNames {
+		if name == s {
+			return b, nil
+		}
+	}
+	return BumpNone, fmt.Errorf("unknown bump %q", s)
+}
+
+// Rules are the versioning rules of a repository.
+type Rules struct {
+	// TagPrefix is the text in front of the semantic version of a tag (ie. "v" in v1.2.3).
+	TagPrefix string
+	// InitialVersion is the version the first release is based on when the repository has no tags.
+	InitialVersion string
+	// Bumps maps a commit type to the part of the version it increments.
+	Bumps map[CommitType]Bump
+	// Paths restricts the commits to the ones touching the git pathspecs, ie. the directory of a nested module.
+	Paths []string
+	// ExcludePrereleases ignores pre-release tags when looking up the latest version.
+	ExcludePrereleases bool
+	// MaxBump is the highest bump allowed, ie. patch on a maintenance branch. BumpNone allows any bump.
+	MaxBump Bump
+	// InitialDevelopment keeps 0.x versions below 1.0.0: while the major is 0 breaking changes bump the minor and
+	// features bump the patch. Graduate releases 1.0.0.
+	InitialDevelopment bool
+}
+
+// DefaultRules are the rules used when a repository does not declare its own.
+func DefaultRules() Rules {
+	return Rules{
+		TagPrefix:      "v",
+		InitialVersion: "0.0.0",
+		Bumps: map[CommitType]Bump{
+			Fix:     BumpPatch,
+			Feature: BumpMinor,
+		},
+	}
+}
+
+// BumpFor returns the Bump a single commit triggers. A Semver-Bump trailer takes precedence over the rules, otherwise
+// breaking commits are always a major bump. Commit types without a rule don't bump the version, the second return value
+// is then false.
+func (r Rules) BumpFor(c Commit) (Bump, bool) {
+	if b, ok := c.BumpOverride(); ok {
+		return b, true
+	}
+	if c.IsBreaking {
+		return BumpMajor, true
+	}
+	b, ok := r.Bumps[c.Type]
+	return b, ok
+}
+
+// VersionBump returns the bump b applies to v. In InitialDevelopment every bump of a 0.x version is lowered by one, a
+// major becomes a minor and a minor a patch.
+func (r Rules) VersionBump(v semver.Version, b Bump) Bump {
+	if !r.InitialDevelopment || v.Major() > 0 || b <= BumpPatch {
+		return b
+	}
+	return b - 1
+}
+
+// CheckBump returns an error wrapping ErrBumpNotAllowed when b is higher than the MaxBump.
+func (r Rules) CheckBump(b Bump) error {
+	if r.MaxBump != BumpNone && b > r.MaxBump {
+		return fmt.Errorf("%w: %s is higher than the allowed %s", ErrBumpNotAllowed, b, r.MaxBump)
+	}
+	return nil
+}
+
+// Scope restricts the repository to the tags and commits the rules apply to.
+func (r Rules) Scope(repo git.Repository) git.Repository {
+	return repo.WithScope(git.Scope{TagPrefix: r.TagPrefix, Paths: r.Paths, ExcludePrereleases: r.ExcludePrereleases})
+}
+
+// ParseTag parses a tag made of the TagPrefix followed by a semantic version. A trailing "v" of the prefix stays
+// on the version so that semver.Version.Original keeps it. Any other tag is an ErrInvalidTag.
+func (r Rules) ParseTag(tag string) (*semver.Version, error) {
+	prefix := strings.TrimSuffix(r.TagPrefix, "v")
+	if !strings.HasPrefix(tag, prefix) {
+		return nil, ErrInvalidTag{Tag: tag}
+	}
+	v, err := semver.NewVersion(strings.TrimPrefix(tag, prefix))
+	if err != nil {
+		return nil, ErrInvalidTag{Tag: tag}
+	}
+	return v, nil
+}
+
+// FormatTag returns the tag name of a version.
+func (r Rules) FormatTag(v semver.Version) string {
+	return r.TagPrefix + v.String()
+}
+
+// initialTag is the tag the first release is based on.
+func (r Rules) initialTag() string {
+	return r.TagPrefix + r.InitialVersion
+}
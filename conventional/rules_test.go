@@ -0,0 +1,86 @@
+package conventional
+
+import (
+	"github.com/Masterminds/semver"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseBump(t *testing.T) {
+	for _, b := range []Bump{BumpNone, BumpPatch, BumpMinor, BumpMajor} {
+		parsed, err := ParseBump(b.String())
+		assert.NoError(t, err)
+		assert.Equal(t, b, parsed)
+	}
+
+	_, err := ParseBump("huge")
+	assert.Error(t, err)
+}
+
+func TestBumpFor(t *testing.T) {
+	rules := DefaultRules()
+
+	b, ok := rules.BumpFor(Commit{Type: Fix})
+	assert.True(t, ok)
+	assert.Equal(t, BumpPatch, b)
+
+	b, ok = rules.BumpFor(Commit{Type: Feature})
+	assert.True(t, ok)
+	assert.Equal(t, BumpMinor, b)
+
+	b, ok = rules.BumpFor(Commit{Type: "chore", IsBreaking: true})
+	assert.True(t, ok)
+	assert.Equal(t, BumpMajor, b)
+
+	_, ok = rules.BumpFor(Commit{Type: "chore"})
+	assert.False(t, ok)
+}
+
+func TestParseTag(t *testing.T) {
+	rules := DefaultRules()
+	v, err := rules.ParseTag("v1.2.3")
+	assert.NoError(t, err)
+	assert.Equal(t, semver.MustParse("v1.2.3"), v)
+	assert.Equal(t, "v1.2.4", rules.FormatTag(v.IncPatch()))
+
+	rules.TagPrefix = "release-"
+	v, err = rules.ParseTag("release-1.2.3")
+	assert.NoError(t, err)
+	assert.Equal(t, semver.MustParse("1.2.3"), v)
+	assert.Equal(t, "release-1.2.4", rules.FormatTag(v.IncPatch()))
+
+	_, err = rules.ParseTag("v1.2.3")
+	var invalid ErrInvalidTag
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "v1.2.3", invalid.Tag)
+}
+
+func TestCheckBump(t *testing.T) {
+	rules := DefaultRules()
+	assert.NoError(t, rules.CheckBump(BumpMajor))
+
+	rules.MaxBump = BumpPatch
+	assert.NoError(t, rules.CheckBump(BumpPatch))
+	assert.ErrorIs(t, rules.CheckBump(BumpMinor), ErrBumpNotAllowed)
+}
+
+func TestBumpBetween(t *testing.T) {
+	v := *semver.MustParse("1.2.3")
+	assert.Equal(t, BumpMajor, BumpBetween(v, *semver.MustParse("2.0.0")))
+	assert.Equal(t, BumpMinor, BumpBetween(v, *semver.MustParse("1.4.0")))
+	assert.Equal(t, BumpPatch, BumpBetween(v, *semver.MustParse("1.2.4-rc.1")))
+	assert.Equal(t, BumpNone, BumpBetween(v, *semver.MustParse("1.2.3+build.1")))
+}
+
+func TestVersionBump(t *testing.T) {
+	rules := DefaultRules()
+	zero, stable := *semver.MustParse("v0.4.2"), *semver.MustParse("v1.4.2")
+	assert.Equal(t, BumpMajor, rules.VersionBump(zero, BumpMajor))
+
+	rules.InitialDevelopment = true
+	assert.Equal(t, BumpMinor, rules.VersionBump(zero, BumpMajor))
+	assert.Equal(t, BumpPatch, rules.VersionBump(zero, BumpMinor))
+	assert.Equal(t, BumpPatch, rules.VersionBump(zero, BumpPatch))
+	assert.Equal(t, BumpNone, rules.VersionBump(zero, BumpNone))
+	assert.Equal(t, BumpMajor, rules.VersionBump(stable, BumpMajor))
+}
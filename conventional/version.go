@@ -1,67 +1,164 @@
 package conventional
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/Masterminds/semver"
 	"github.com/hooliganlin/versioning/semversioner/git"
 )
-const initialTag = "v0.0.0"
+
+// ErrNoRelease is returned by DetermineNextVersion when none of the commits since the latest tag warrant a release.
+var ErrNoRelease = errors.New("no releasable commits since the latest tag")
+
+// ErrGraduated is returned by Graduate for versions which are already 1.0.0 or higher.
+var ErrGraduated = errors.New("version has already graduated to 1.0.0")
+
 // DetermineNextVersion leverages the conventional commit style logs to determine
 // the next semantic version based on the commits since the latest tag.
-// Any commits with a fix will always warrant a patch.
-func DetermineNextVersion(workDir string) (semver.Version, error) {
-	g := git.New(workDir)
-	latestTag, err := g.GetLatestTag()
+// The highest bump of all commits wins (breaking > feat > fix). Commits reverted within the range don't count. When
+// no commit warrants a release the latest version is returned together with ErrNoRelease. A bump higher than the
+// rules' MaxBump is an ErrBumpNotAllowed. In the rules' InitialDevelopment a 0.x version never reaches 1.0.0, see
+// Rules.VersionBump.
+// A Semver-Bump trailer overrides the bump of its commit and the newest Release-As trailer overrides the next version
+// altogether, it must be higher than the latest version and within the MaxBump. The repository is scoped to the rules,
+// see Rules.Scope. Any git.Repository works, ie. a git.Memory to test rules against a commit graph described in code.
+// The commits are streamed through Repository.ForEachCommitSinceLatestTag, only the reverts still waiting for the
+// commit they revert are held in memory.
+func DetermineNextVersion(ctx context.Context, repo git.Repository, rules Rules) (semver.Version, error) {
+	repo = rules.Scope(repo)
+	latestTag, err := repo.GetLatestTag(ctx)
 	if err != nil {
 		return semver.Version{}, err
 	}
-	if latestTag == "" {
-		v, err := semver.NewVersion(initialTag)
+	// the commits are folded as they are read, only the reverts waiting for the commit they revert are held
+	d := decision{rules: rules}
+	var reverts revertWalk
+	err = repo.ForEachCommitSinceLatestTag(ctx, func(gitCommit git.Commit) error {
+		c := NewCommit(gitCommit)
+		index := reverts.walked
+		if _, reverted := reverts.next(c); !reverted && !c.IsRevert() {
+			d.add(index, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return semver.Version{}, err
+	}
+	// reverts of commits outside the range count like any commit
+	for _, revert := range reverts.pending {
+		d.add(revert.index, revert.Commit)
+	}
+	if d.err != nil {
+		return semver.Version{}, d.err
+	}
+
+	tag := latestTag
+	if tag == "" {
+		tag = rules.initialTag()
+	}
+	v, err := rules.ParseTag(tag)
+	if err != nil {
+		return semver.Version{}, err
+	}
+	if d.releaseAs != nil {
+		next, err := releaseAs(*v, *d.releaseAs)
 		if err != nil {
 			return semver.Version{}, err
 		}
+		if err = rules.CheckBump(BumpBetween(*v, next)); err != nil {
+			return semver.Version{}, fmt.Errorf("%s: %w", d.releaseAs, err)
+		}
+		return next, nil
+	}
+	if latestTag == "" {
 		return v.IncPatch(), nil
 	}
 
-	v, err := semver.NewVersion(latestTag)
-	if err != nil {
-		return semver.Version{}, err
+	bump := rules.VersionBump(*v, d.bump)
+	if bump == BumpNone {
+		return *v, ErrNoRelease
 	}
-	commits, err := g.GetCommitsSinceLatestTag()
-	if err != nil {
+	if err = rules.CheckBump(bump); err != nil {
 		return semver.Version{}, err
 	}
+	return bump.Apply(*v), nil
+}
 
-	breaking, nonBreaking := partitionCommits(mapCommits(commits, NewCommit), isBreakingCommit)
-	if len(breaking) > 0 {
-		return v.IncMajor(), nil
+// decision folds the commits since the latest tag, without the reverted ones, into what decides the next version: the
+// highest bump, the newest Release-As trailer and the first invalid override trailer. The index of a commit in the
+// range tells which is the newest, the reverts of commits outside the range are only added at the end.
+type decision struct {
+	rules          Rules
+	bump           Bump
+	releaseAs      *Override
+	releaseAsIndex int
+	err            error
+	errIndex       int
+}
+
+func (d *decision) add(index int, c Commit) {
+	if err := checkOverrides([]Commit{c}); err != nil && (d.err == nil || index < d.errIndex) {
+		d.err, d.errIndex = err, index
 	}
-	fixes, _ :=  partitionCommits(nonBreaking, hasFixCommit)
-	if len(fixes) > 0 {
-		return v.IncPatch(), nil
+	if o, ok := c.override(ReleaseAsToken); ok && (d.releaseAs == nil || index < d.releaseAsIndex) {
+		d.releaseAs, d.releaseAsIndex = &o, index
 	}
+	if b, _ := d.rules.BumpFor(c); b > d.bump {
+		d.bump = b
+	}
+}
 
-	return v.IncMinor(), nil
+// releaseAs returns the version of the Release-As override, which must be higher than the latest version v.
+func releaseAs(v semver.Version, o Override) (semver.Version, error) {
+	next, err := o.Version()
+	if err != nil {
+		return semver.Version{}, err
+	}
+	if !next.GreaterThan(&v) {
+		return semver.Version{}, fmt.Errorf("%s is not higher than the latest version %s", o, v.String())
+	}
+	return *next, nil
 }
 
-func isBreakingCommit(c Commit) bool {
-	return c.IsBreaking
+// Graduate returns 1.0.0, the first stable release of a 0.x version or of a 1.0.0 pre-release. Other versions are
+// already stable and return ErrGraduated.
+func Graduate(v semver.Version) (semver.Version, error) {
+	stable := *semver.MustParse("1.0.0")
+	if v.Major() > 0 && !(v.Prerelease() != "" && v.Major() == 1 && v.Minor() == 0 && v.Patch() == 0) {
+		return semver.Version{}, fmt.Errorf("%w: %s", ErrGraduated, v.String())
+	}
+	return stable, nil
 }
-func hasFixCommit(c Commit) bool {
-	return c.Type == Fix
+
+// DetermineBump returns the highest bump triggered by the commits. Commits without a rule don't bump the version.
+func DetermineBump(commits []Commit, rules Rules) Bump {
+	bump := BumpNone
+	for _, c := range commits {
+		if b, _ := rules.BumpFor(c); b > bump {
+			bump = b
+		}
+	}
+	return bump
 }
-// partitionCommits takes in a slice c and applies a conditional function, f to return the results as two slices. The left
-// slice is the result of a truthy result from f. A falsy result yields the right slice.
-func partitionCommits(c []Commit, f func(c Commit) bool) ([]Commit, []Commit) {
-	var left []Commit
-	var right []Commit
-	for _, e := range c {
-		if f(e) {
-			left = append(left, e)
-		} else {
-			right = append(right, e)
+
+// DecisiveCommit returns the first commit triggering the bump b. The second return value is false when no commit does,
+// which is always the case for BumpNone.
+func DecisiveCommit(commits []Commit, rules Rules, b Bump) (Commit, bool) {
+	if b == BumpNone {
+		return Commit{}, false
+	}
+	for _, c := range commits {
+		if bump, _ := rules.BumpFor(c); bump == b {
+			return c, true
 		}
 	}
-	return left, right
+	return Commit{}, false
+}
+
+// ParseCommits converts every git.Commit into a conventional Commit, keeping the original order.
+func ParseCommits(c []git.Commit) []Commit {
+	return mapCommits(c, NewCommit)
 }
 
 func mapCommits(c []git.Commit, f func(c git.Commit) Commit) []Commit {
@@ -1,17 +1,18 @@
 package conventional
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/Masterminds/semver"
 	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"math/rand"
-	"os"
-	"os/exec"
 	"strings"
 	"testing"
 )
-func(s GitTestSuite) TestDetermineNextVersion() {
+func(s VersionTestSuite) TestDetermineNextVersion() {
 	s.Run("patch", func() {
 		s.runDetermineNextVersionTest("no previous tag",
 			[]GenCommitFunction{genFixCommit},
@@ -35,13 +36,8 @@ func(s GitTestSuite) TestDetermineNextVersion() {
 			"v1.0.1",
 		)
 
-		s.runDetermineNextVersionTest("multiple fixes and one feat",
-			[]GenCommitFunction{genFixCommit, genFixCommit, genFeatCommit, genFixCommit},
-			"v1.0.0",
-			"v1.0.1",
-		)
-		s.runDetermineNextVersionTest("multiple feats and one fix",
-			[]GenCommitFunction{genFeatCommit, genFixCommit, genFeatCommit, genFeatCommit},
+		s.runDetermineNextVersionTest("fix and ignored commits",
+			[]GenCommitFunction{genChoreCommit, genFixCommit, genChoreCommit},
 			"v1.0.0",
 			"v1.0.1",
 		)
@@ -65,6 +61,17 @@ func(s GitTestSuite) TestDetermineNextVersion() {
 			"v1.11.5",
 			"v1.12.0",
 		)
+
+		s.runDetermineNextVersionTest("multiple fixes and one feat",
+			[]GenCommitFunction{genFixCommit, genFixCommit, genFeatCommit, genFixCommit},
+			"v1.0.0",
+			"v1.1.0",
+		)
+		s.runDetermineNextVersionTest("multiple feats and one fix",
+			[]GenCommitFunction{genFeatCommit, genFixCommit, genFeatCommit, genFeatCommit},
+			"v1.0.0",
+			"v1.1.0",
+		)
 	})
 
 	s.Run("major", func() {
@@ -93,78 +100,311 @@ func(s GitTestSuite) TestDetermineNextVersion() {
 		)
 	})
 }
+func(s VersionTestSuite) TestDetermineNextVersionWithRules() {
+	ctx := context.Background()
+	s.SetupTest()
+	rules := Rules{
+		TagPrefix:      "release-",
+		InitialVersion: "1.0.0",
+		Bumps: map[CommitType]Bump{
+			Feature: BumpMinor,
+			"perf":  BumpPatch,
+			Fix:     BumpNone,
+		},
+	}
+
+	if _, err := s.Repo.CreateCommit(ctx, genFeatCommit(0), "", true); err != nil {
+		s.FailNow("could not create initial commit", err)
+	}
+	v, err := DetermineNextVersion(ctx, s.Repo, rules)
+	s.NoError(err)
+	s.Equal("1.0.1", v.String())
+
+	if err = s.Repo.CreateTag(ctx, "release-1.2.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if err = s.Repo.CreateTag(ctx, "v9.0.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if _, err = s.Repo.CreateCommit(ctx, "perf: faster", "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	if _, err = s.Repo.CreateCommit(ctx, genFixCommit(1), "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	v, err = DetermineNextVersion(ctx, s.Repo, rules)
+	s.NoError(err)
+	s.Equal("1.2.1", v.String())
+}
+
+func(s VersionTestSuite) TestDetermineNextVersionNoRelease() {
+	ctx := context.Background()
+	s.Run("only ignored commits", func() {
+		s.SetupTest()
+		if _, err := s.Repo.CreateCommit(ctx, genFeatCommit(0), "", true); err != nil {
+			s.FailNow("could not create initial commit", err)
+		}
+		if err := s.Repo.CreateTag(ctx, "v1.2.3", false); err != nil {
+			s.FailNow("could not create tag", err)
+		}
+		for i, fn := range []GenCommitFunction{genChoreCommit, genChoreCommit} {
+			if _, err := s.Repo.CreateCommit(ctx, fn(i+1), genCommitBody(), true); err != nil {
+				s.FailNowf("could not create commit", "commit %d err=%v", i+1, err)
+			}
+		}
+
+		v, err := DetermineNextVersion(ctx, s.Repo, DefaultRules())
+		s.ErrorIs(err, ErrNoRelease)
+		s.Equal("1.2.3", v.String())
+	})
+
+	s.Run("no commits", func() {
+		s.SetupTest()
+		if _, err := s.Repo.CreateCommit(ctx, genFeatCommit(0), "", true); err != nil {
+			s.FailNow("could not create initial commit", err)
+		}
+		if err := s.Repo.CreateTag(ctx, "v1.2.3", false); err != nil {
+			s.FailNow("could not create tag", err)
+		}
+
+		_, err := DetermineNextVersion(ctx, s.Repo, DefaultRules())
+		s.ErrorIs(err, ErrNoRelease)
+	})
+}
+
+func(s VersionTestSuite) TestDetermineNextVersionWithReverts() {
+	ctx := context.Background()
+	s.SetupTest()
+	if _, err := s.Repo.CreateCommit(ctx, genFeatCommit(0), "", true); err != nil {
+		s.FailNow("could not create initial commit", err)
+	}
+	if err := s.Repo.CreateTag(ctx, "v1.2.3", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if _, err := s.Repo.CreateCommit(ctx, genFixCommit(1), "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	feat, err := s.Repo.CreateCommit(ctx, genFeatCommit(2), "", true)
+	if err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	revert := fmt.Sprintf("This reverts commit %s.", feat.Hash)
+	if _, err = s.Repo.CreateCommit(ctx, fmt.Sprintf("Revert \"%s\"", feat.Subject), revert, true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+
+	v, err := DetermineNextVersion(ctx, s.Repo, DefaultRules())
+	s.NoError(err)
+	s.Equal("1.2.4", v.String())
+}
+
+// streamedOnly is a git.Repository which refuses to hold the commits since the latest tag in memory.
+type streamedOnly struct {
+	git.Repository
+}
+
+func (r streamedOnly) GetCommitsSinceLatestTag(context.Context) ([]git.Commit, error) {
+	return nil, errors.New("the commits must be streamed")
+}
+
+func (r streamedOnly) WithScope(scope git.Scope) git.Repository {
+	return streamedOnly{r.Repository.WithScope(scope)}
+}
+
+func(s VersionTestSuite) TestDetermineNextVersionStreamsCommits() {
+	ctx := context.Background()
+	s.SetupTest()
+	if _, err := s.Repo.CreateCommit(ctx, genFeatCommit(0), "", true); err != nil {
+		s.FailNow("could not create initial commit", err)
+	}
+	if err := s.Repo.CreateTag(ctx, "v1.2.3", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	for _, c := range []struct{ subject, body string }{
+		{"chore: release", "Release-As: 1.5.0"},
+		{genFeatCommit(1), ""},
+		// the revert of a commit before the tag counts where it is in the range, its Release-As is the newest
+		{"Revert \"feat: before the tag\"", "This reverts commit 0123456.\n\nRelease-As: 1.6.0"},
+		{genFixCommit(2), ""},
+	} {
+		if _, err := s.Repo.CreateCommit(ctx, c.subject, c.body, true); err != nil {
+			s.FailNow("could not create commit", err)
+		}
+	}
+
+	v, err := DetermineNextVersion(ctx, streamedOnly{s.Repo}, DefaultRules())
+	s.NoError(err)
+	s.Equal("1.6.0", v.String())
+}
+
+func(s VersionTestSuite) TestDetermineNextVersionInitialDevelopment() {
+	ctx := context.Background()
+	s.SetupTest()
+	rules := DefaultRules()
+	rules.InitialDevelopment = true
+
+	if _, err := s.Repo.CreateCommit(ctx, genFeatCommit(0), "", true); err != nil {
+		s.FailNow("could not create initial commit", err)
+	}
+	if err := s.Repo.CreateTag(ctx, "v0.4.2", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if _, err := s.Repo.CreateCommit(ctx, genFeatCommit(1), "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	v, err := DetermineNextVersion(ctx, s.Repo, rules)
+	s.NoError(err)
+	s.Equal("0.4.3", v.String())
+
+	if _, err = s.Repo.CreateCommit(ctx, genBreakingCommit(2), "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	v, err = DetermineNextVersion(ctx, s.Repo, rules)
+	s.NoError(err)
+	s.Equal("0.5.0", v.String())
+
+	if err = s.Repo.CreateTag(ctx, "v1.0.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if _, err = s.Repo.CreateCommit(ctx, genBreakingCommit(3), "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	v, err = DetermineNextVersion(ctx, s.Repo, rules)
+	s.NoError(err)
+	s.Equal("2.0.0", v.String())
+}
+
+func(s VersionTestSuite) TestDetermineNextVersionOverrides() {
+	ctx := context.Background()
+	s.SetupTest()
+
+	if _, err := s.Repo.CreateCommit(ctx, genFeatCommit(0), "", true); err != nil {
+		s.FailNow("could not create initial commit", err)
+	}
+	if err := s.Repo.CreateTag(ctx, "v1.2.3", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if _, err := s.Repo.CreateCommit(ctx, genFixCommit(1), "Semver-Bump: major", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	v, err := DetermineNextVersion(ctx, s.Repo, DefaultRules())
+	s.NoError(err)
+	s.Equal("2.0.0", v.String())
+
+	if _, err = s.Repo.CreateCommit(ctx, "chore: release", "Release-As: 1.5.0", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	v, err = DetermineNextVersion(ctx, s.Repo, DefaultRules())
+	s.NoError(err)
+	s.Equal("1.5.0", v.String())
+
+	// a maintenance branch can't be released as a higher major or minor either
+	maintenance := DefaultRules()
+	maintenance.MaxBump = BumpPatch
+	_, err = DetermineNextVersion(ctx, s.Repo, maintenance)
+	s.ErrorIs(err, ErrBumpNotAllowed)
+	maintenance.MaxBump = BumpMinor
+	v, err = DetermineNextVersion(ctx, s.Repo, maintenance)
+	s.NoError(err)
+	s.Equal("1.5.0", v.String())
+
+	lower, err := s.Repo.CreateCommit(ctx, "chore: release", "Release-As: 1.0.0", true)
+	if err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	_, err = DetermineNextVersion(ctx, s.Repo, DefaultRules())
+	s.EqualError(err, fmt.Sprintf("Release-As: 1.0.0 in commit %s is not higher than the latest version 1.2.3",
+		lower.ShortHash()))
+
+	if _, err = s.Repo.CreateCommit(ctx, genFixCommit(2), "Semver-Bump: huge", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	_, err = DetermineNextVersion(ctx, s.Repo, DefaultRules())
+	s.Error(err)
+}
+
+func TestGraduate(t *testing.T) {
+	v, err := Graduate(*semver.MustParse("v0.4.2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", v.String())
+
+	v, err = Graduate(*semver.MustParse("v1.0.0-rc.2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", v.String())
+
+	_, err = Graduate(*semver.MustParse("v1.0.0"))
+	assert.ErrorIs(t, err, ErrGraduated)
+}
+
+func TestDetermineBump(t *testing.T) {
+	rules := DefaultRules()
+	assert.Equal(t, BumpNone, DetermineBump(nil, rules))
+	assert.Equal(t, BumpNone, DetermineBump([]Commit{{Type: "chore"}, {Type: "docs"}}, rules))
+	assert.Equal(t, BumpPatch, DetermineBump([]Commit{{Type: "chore"}, {Type: Fix}}, rules))
+	assert.Equal(t, BumpMinor, DetermineBump([]Commit{{Type: Fix}, {Type: Feature}, {Type: Fix}}, rules))
+	assert.Equal(t, BumpMajor, DetermineBump([]Commit{{Type: Feature}, {Type: "chore", IsBreaking: true}}, rules))
+}
+
+func TestDecisiveCommit(t *testing.T) {
+	rules := DefaultRules()
+	commits := []Commit{{Type: Fix, Title: "fix 1"}, {Type: Feature, Title: "feat 1"}, {Type: Feature, Title: "feat 2"}}
+
+	c, ok := DecisiveCommit(commits, rules, BumpMinor)
+	assert.True(t, ok)
+	assert.Equal(t, commits[1], c)
+
+	_, ok = DecisiveCommit(commits, rules, BumpMajor)
+	assert.False(t, ok)
+
+	_, ok = DecisiveCommit([]Commit{{Type: "chore"}}, rules, BumpNone)
+	assert.False(t, ok)
+}
+
 type GenCommitFunction func(num int) string
-func (s GitTestSuite) runDetermineNextVersionTest(
+func (s VersionTestSuite) runDetermineNextVersionTest(
 	name string,
 	commitFunc []GenCommitFunction,
 	initialTag string,
 	expectedVersion string) {
+	ctx := context.Background()
 	s.SetupTest()
 	s.Run(name, func() {
-		if _, err := s.Git.CreateCommit(genFeatCommit(0), genCommitBody(), true); err != nil {
+		if _, err := s.Repo.CreateCommit(ctx, genFeatCommit(0), genCommitBody(), true); err != nil {
 			s.Error(err, "could not create initial commit")
 		}
 
 		if initialTag != "" {
-			if err := s.Git.CreateTag(initialTag, false); err != nil {
+			if err := s.Repo.CreateTag(ctx, initialTag, false); err != nil {
 				s.Errorf(err, "could not create %s initialTag", initialTag)
 			}
 		}
 		for i, fn := range commitFunc {
-			if _, err := s.Git.CreateCommit(fn(i+1), genCommitBody(), true); err != nil {
+			if _, err := s.Repo.CreateCommit(ctx, fn(i+1), genCommitBody(), true); err != nil {
 				s.Errorf(err, "could not create commit %d", i+1)
 			}
 		}
 
-		v, err := DetermineNextVersion(s.Git.WorkDirectory)
+		v, err := DetermineNextVersion(ctx, s.Repo, DefaultRules())
 		if err != nil {
 			s.Error(err, "could not determine next version")
 		}
 
 		s.Equal(*semver.MustParse(expectedVersion), v)
 	})
-	s.TearDownTest()
 }
 
 func TestRunSemverTest(t *testing.T) {
-	suite.Run(t, new(GitTestSuite))
+	suite.Run(t, new(VersionTestSuite))
 }
 
-type GitTestSuite struct {
+// VersionTestSuite determines versions of a git.Memory repository, every test starts with an empty one.
+type VersionTestSuite struct {
 	suite.Suite
-	Git git.Git
+	Repo git.Memory
 }
 
-// Make sure that VariableThatShouldStartAtFive is set to five
-// before each test
-func (s *GitTestSuite) SetupTest() {
-	tempDir, err := os.MkdirTemp("", "")
-	if err != nil {
-		s.FailNow("could not create temporary directory", err)
-	}
-	if err = os.Chdir(tempDir); err != nil {
-		s.FailNow("could not change to temporary directory", err)
-	}
-
-	if err != nil {
-		s.FailNow("cannot setup working directory", err)
-	}
-	if err = exec.Command("git", "init").Run(); err != nil {
-		s.FailNow("could not initialize git repository err", err)
-	}
-	if err != nil {
-		if err = os.RemoveAll(tempDir); err != nil {
-			s.FailNowf("could not remove working directory", "dir=%s err=%v", tempDir, err)
-		}
-		s.FailNow("cannot create new git repo", err)
-	}
-	s.Git = git.New(tempDir)
-}
-
-func (s *GitTestSuite) TearDownTest() {
-	if err := os.RemoveAll(s.Git.WorkDirectory); err != nil {
-		s.T().Fatalf("could not remove temporary directory %s err=%v", s.Git.WorkDirectory, err)
-	}
+func (s *VersionTestSuite) SetupTest() {
+	s.Repo = git.NewMemory()
 }
 
 func genFixCommit(num int) string {
@@ -179,6 +419,12 @@ func genFeatCommit(num int) string {
 	return fmt.Sprintf("%s: %s", prefix, subject)
 }
 
+func genChoreCommit(num int) string {
+	prefix := "chore"
+	subject := fmt.Sprintf("this is chore number %d", num)
+	return fmt.Sprintf("%s: %s", prefix, subject)
+}
+
 func genBreakingCommit(num int) string {
 	prefix := "feat!"
 	subject := fmt.Sprintf("this is a breaking change %d", num)
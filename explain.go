@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// printExplanation writes every commit since the previous tag with its parsed fields and the rule it triggered, ie.
+//	Commits since v0.1.8:
+//	HASH     TYPE  SCOPE  BREAKING  RULE                       OUTCOME
+//	fb067b1  feat  scope  true      breaking change => major   decisive
+//	c100381  feat  scope  false     feat => minor
+//	574a7e2               false     not a conventional commit  ignored
+//	Bump: major, decided by fb067b1 feat(scope)!: this is a test description that breaks
+func printExplanation(w io.Writer, previousTag string, e conventional.Explanation) error {
+	if previousTag == "" {
+		previousTag = "the first commit"
+	}
+	var table strings.Builder
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "HASH\tTYPE\tSCOPE\tBREAKING\tRULE\tOUTCOME")
+	var decisive *conventional.CommitExplanation
+	for i, c := range e.Commits {
+		outcome := ""
+		switch {
+		case c.Decisive:
+			outcome = "decisive"
+			decisive = &e.Commits[i]
+		case c.Ignored:
+			outcome = "ignored"
+		}
+		commitType := ""
+		if c.Commit.IsConventional() {
+			commitType = string(c.Commit.Type)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\n",
+			c.Commit.ShortHash(), commitType, c.Commit.Scope, c.Commit.IsBreaking, c.Rule, outcome)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Commits since %s:\n", previousTag))
+	for _, line := range strings.SplitAfter(table.String(), "\n") {
+		// commits without an outcome leave the padding of the last column behind
+		if strings.HasSuffix(line, "\n") {
+			line = strings.TrimRight(strings.TrimSuffix(line, "\n"), " ") + "\n"
+		}
+		sb.WriteString(line)
+	}
+
+	if e.Override != nil {
+		sb.WriteString(fmt.Sprintf("Override: %s\n", e.Override))
+	}
+	switch {
+	case decisive != nil && e.Override != nil && e.Override.Token == conventional.ReleaseAsToken:
+		sb.WriteString(fmt.Sprintf("Release as %s, decided by %s %s\n", e.Override.Value,
+			decisive.Commit.ShortHash(), decisive.Commit.Subject))
+	case decisive != nil && e.Reason != "":
+		sb.WriteString(fmt.Sprintf("Bump: %s, decided by %s %s, %s\n", e.Bump, decisive.Commit.ShortHash(),
+			decisive.Commit.Subject, e.Reason))
+	case decisive != nil:
+		sb.WriteString(fmt.Sprintf("Bump: %s, decided by %s %s\n", e.Bump, decisive.Commit.ShortHash(),
+			decisive.Commit.Subject))
+	case e.Reason != "":
+		sb.WriteString(fmt.Sprintf("Bump: %s, %s\n", e.Bump, e.Reason))
+	default:
+		sb.WriteString(fmt.Sprintf("Bump: %s, no commit warrants a release\n", e.Bump))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
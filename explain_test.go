@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPrintExplanation(t *testing.T) {
+	commits := conventional.ParseCommits([]git.Commit{
+		{Subject: "feat(scope)!: this is a test description that breaks", Hash: "fb067b14f2e9d24fee367651603424a8304a0845"},
+		{Subject: "feat(scope): this is a new feature", Hash: "c1003815c1cfe3c0cd718550573031a8bf536188"},
+		{Subject: "This is not a conventional commit", Hash: "574a7e20eeaac9905bbf0fd3486ab13a7a0368c3"},
+	})
+
+	var b bytes.Buffer
+	err := printExplanation(&b, "v0.1.8", conventional.Explain(commits, conventional.DefaultRules(), "v0.1.8"))
+	assert.NoError(t, err)
+	assert.Equal(t, `Commits since v0.1.8:
+HASH     TYPE  SCOPE  BREAKING  RULE                       OUTCOME
+fb067b1  feat  scope  true      breaking change => major   decisive
+c100381  feat  scope  false     feat => minor
+574a7e2               false     not a conventional commit  ignored
+Bump: major, decided by fb067b1 feat(scope)!: this is a test description that breaks
+`, b.String())
+
+	b.Reset()
+	err = printExplanation(&b, "v0.1.8", conventional.Explain(commits[2:], conventional.DefaultRules(), "v0.1.8"))
+	assert.NoError(t, err)
+	assert.Equal(t, `Commits since v0.1.8:
+HASH     TYPE  SCOPE  BREAKING  RULE                       OUTCOME
+574a7e2               false     not a conventional commit  ignored
+Bump: none, no commit warrants a release
+`, b.String())
+
+	b.Reset()
+	err = printExplanation(&b, "", conventional.Explain(commits, conventional.DefaultRules(), ""))
+	assert.NoError(t, err)
+	assert.Equal(t, `Commits since the first commit:
+HASH     TYPE  SCOPE  BREAKING  RULE                       OUTCOME
+fb067b1  feat  scope  true      breaking change => major
+c100381  feat  scope  false     feat => minor
+574a7e2               false     not a conventional commit  ignored
+Bump: patch, first release after the initial version 0.0.0
+`, b.String())
+
+	rules := conventional.DefaultRules()
+	rules.InitialDevelopment = true
+	b.Reset()
+	err = printExplanation(&b, "v0.1.8", conventional.Explain(commits[:1], rules, "v0.1.8"))
+	assert.NoError(t, err)
+	assert.Equal(t, `Commits since v0.1.8:
+HASH     TYPE  SCOPE  BREAKING  RULE                      OUTCOME
+fb067b1  feat  scope  true      breaking change => major  decisive
+Bump: minor, decided by fb067b1 feat(scope)!: this is a test description that breaks, lowered to a minor while the major is 0
+`, b.String())
+
+	release := conventional.NewCommit(git.Commit{Subject: "chore: release", Body: "Release-As: 2.0.0",
+		Hash: "3f5c7a1d2e9b4c6a8d0f1e2b3c4d5e6f7a8b9c0d"})
+	b.Reset()
+	err = printExplanation(&b, "v0.1.8",
+		conventional.Explain(append(commits[1:2], release), conventional.DefaultRules(), "v0.1.8"))
+	assert.NoError(t, err)
+	assert.Equal(t, `Commits since v0.1.8:
+HASH     TYPE   SCOPE  BREAKING  RULE               OUTCOME
+c100381  feat   scope  false     feat => minor
+3f5c7a1  chore         false     Release-As: 2.0.0  decisive
+Override: Release-As: 2.0.0 in commit 3f5c7a1
+Release as 2.0.0, decided by 3f5c7a1 chore: release
+`, b.String())
+}
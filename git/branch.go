@@ -0,0 +1,72 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+)
+
+// ErrDetachedHead is returned when HEAD is detached and no CI environment variable names the branch.
+var ErrDetachedHead = errors.New("HEAD is detached and no CI branch variable is set")
+
+// BranchEnvVars are the environment variables CI systems set to the name of the branch being built, in order of
+// preference. They are used when HEAD is detached, which is how most CI systems check out a commit.
+var BranchEnvVars = []string{
+	"GITHUB_HEAD_REF",    // GitHub Actions pull requests
+	"GITHUB_REF_NAME",    // GitHub Actions
+	"CI_COMMIT_REF_NAME", // GitLab CI
+	"BITBUCKET_BRANCH",   // Bitbucket Pipelines
+	"BUILDKITE_BRANCH",   // Buildkite
+	"CIRCLE_BRANCH",      // CircleCI
+	"TRAVIS_BRANCH",      // Travis CI
+	"BRANCH_NAME",        // Jenkins multibranch pipelines
+	"GIT_BRANCH",         // Jenkins git plugin, ie. origin/main
+}
+
+// CurrentBranch returns the name of the checked out branch. When HEAD is detached the branch is read from the
+// BranchEnvVars instead. With a Ref the branch is the one it names, ie. feature for origin/feature, a Ref naming a tag
+// or a commit is like a detached HEAD.
+func (g Git) CurrentBranch(ctx context.Context) (string, error) {
+	if g.Ref != "" {
+		return g.refBranch(ctx)
+	}
+	out, err := g.output(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	branch := strings.TrimSuffix(string(out), "\n")
+	if branch != "HEAD" {
+		return branch, nil
+	}
+	return envBranch()
+}
+
+// refBranch returns the name of the local or remote branch the Ref names, the BranchEnvVars decide for anything else.
+func (g Git) refBranch(ctx context.Context) (string, error) {
+	out, err := g.output(ctx, "rev-parse", "--symbolic-full-name", g.Ref)
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(string(out), "\n")
+	if branch := strings.TrimPrefix(name, "refs/heads/"); branch != name {
+		return branch, nil
+	}
+	if branch := strings.TrimPrefix(name, "refs/remotes/"); branch != name {
+		// the remote comes first, ie. refs/remotes/origin/feature
+		if i := strings.IndexByte(branch, '/'); i >= 0 {
+			return branch[i+1:], nil
+		}
+	}
+	return envBranch()
+}
+
+// envBranch returns the branch named by the first of the BranchEnvVars which is set, ErrDetachedHead without any.
+func envBranch() (string, error) {
+	for _, name := range BranchEnvVars {
+		if b := os.Getenv(name); b != "" {
+			return strings.TrimPrefix(strings.TrimPrefix(b, "refs/heads/"), "origin/"), nil
+		}
+	}
+	return "", ErrDetachedHead
+}
@@ -0,0 +1,83 @@
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+func (s *BranchTestSuite) TestCurrentBranch() {
+	ctx := context.Background()
+	for _, name := range BranchEnvVars {
+		s.T().Setenv(name, "")
+	}
+	c, err := s.Git.CreateCommit(ctx, "first commit", "", true)
+	if err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	if err = s.Git.exec(ctx, "checkout", "-q", "-b", "feature/x").Run(); err != nil {
+		s.FailNow("could not create branch", err)
+	}
+
+	branch, err := s.Git.CurrentBranch(ctx)
+	s.NoError(err)
+	s.Equal("feature/x", branch)
+
+	if err = s.Git.exec(ctx, "checkout", "-q", "--detach", c.Hash).Run(); err != nil {
+		s.FailNow("could not detach HEAD", err)
+	}
+	_, err = s.Git.CurrentBranch(ctx)
+	s.ErrorIs(err, ErrDetachedHead)
+
+	s.T().Setenv("GIT_BRANCH", "origin/release/1.x")
+	branch, err = s.Git.CurrentBranch(ctx)
+	s.NoError(err)
+	s.Equal("release/1.x", branch)
+
+	s.T().Setenv("GITHUB_REF_NAME", "develop")
+	branch, err = s.Git.CurrentBranch(ctx)
+	s.NoError(err)
+	s.Equal("develop", branch)
+}
+
+func (s *BranchTestSuite) TestCurrentBranchOfRef() {
+	ctx := context.Background()
+	for _, name := range BranchEnvVars {
+		s.T().Setenv(name, "")
+	}
+	c, err := s.Git.CreateCommit(ctx, "first commit", "", true)
+	if err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	for _, args := range [][]string{
+		{"branch", "release/1.x"},
+		{"update-ref", "refs/remotes/origin/feature/y", c.Hash},
+		{"tag", "v1.0.0"},
+	} {
+		if err = s.Git.exec(ctx, args[0], args[1:]...).Run(); err != nil {
+			s.FailNow("could not create ref", err)
+		}
+	}
+
+	branch, err := s.Git.WithRef("release/1.x").CurrentBranch(ctx)
+	s.NoError(err)
+	s.Equal("release/1.x", branch)
+	branch, err = s.Git.WithRef("origin/feature/y").CurrentBranch(ctx)
+	s.NoError(err)
+	s.Equal("feature/y", branch)
+
+	_, err = s.Git.WithRef("v1.0.0").CurrentBranch(ctx)
+	s.ErrorIs(err, ErrDetachedHead)
+	s.T().Setenv("GITHUB_HEAD_REF", "pr-branch")
+	branch, err = s.Git.WithRef(c.Hash).CurrentBranch(ctx)
+	s.NoError(err)
+	s.Equal("pr-branch", branch)
+}
+
+func TestBranchTestSuite(t *testing.T) {
+	suite.Run(t, new(BranchTestSuite))
+}
+
+type BranchTestSuite struct {
+	GitTestSuite
+}
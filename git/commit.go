@@ -1,8 +1,10 @@
 package git
 
 import (
+	"bufio"
+	"context"
 	"fmt"
-	"github.com/Masterminds/semver"
+	"io"
 	"strings"
 	"time"
 )
@@ -20,50 +22,102 @@ type Commit struct {
 	Date time.Time
 }
 
-// commitLogFormat is a tab delimited format of a git commit message.
-const commitSeparator = "~~"
-const commitLogFormat = "%+cI%+H%+an%+ae%+s%+b" + commitSeparator
+// shortHashLength is the length of an abbreviated commit hash.
+const shortHashLength = 7
 
-// GetCommitsSinceLatestTag fetches all the commits since the latest tag.
-func (g Git) GetCommitsSinceLatestTag() ([]Commit, error) {
-	describeArgs := []string{"--tags", "--abbrev=0"}
-	output, err := g.exec("describe", describeArgs...).Output()
+// ShortHash returns the hash abbreviated to 7 characters, ie. fb067b1
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > shortHashLength {
+		return c.Hash[:shortHashLength]
+	}
+	return c.Hash
+}
+
+// commitLogFormat prints the fields of a commit each terminated by a NUL, which git doesn't allow in commit messages.
+// The fields are the committer date, hash, author name, author email, subject and body.
+const commitLogFormat = "%cI%x00%H%x00%an%x00%ae%x00%s%x00%b%x00"
+
+// commitFields is the number of fields of a commit in the commitLogFormat.
+const commitFields = 6
+
+// GetCommitsSinceLatestTag fetches all the commits since the latest tag. When the repository has no tags yet, every
+// commit reachable from HEAD, or from the Ref when set, is returned. Only the commits touching the Paths are returned
+// when set, the native backend leaves filtering them to git. ForEachCommitSinceLatestTag walks the same commits without
+// holding them in memory.
+func (g Git) GetCommitsSinceLatestTag(ctx context.Context) ([]Commit, error) {
+	commits := make([]Commit, 0)
+	err := g.ForEachCommitSinceLatestTag(ctx, func(c Commit) error {
+		commits = append(commits, c)
+		return nil
+	})
 	if err != nil {
 		return []Commit{}, err
 	}
-	tag := strings.TrimSuffix(string(output), "\n")
-	_, err = semver.NewVersion(tag)
+	return commits, nil
+}
+
+// ForEachCommitSinceLatestTag calls fn with each commit GetCommitsSinceLatestTag returns, newest first, as soon as it
+// is read. An error returned by fn stops the walk and is returned as is.
+func (g Git) ForEachCommitSinceLatestTag(ctx context.Context, fn func(Commit) error) error {
+	if g.Backend == BackendNative && len(g.Paths) == 0 {
+		return g.native(func(r *repository) error {
+			return r.forEachCommitSinceLatestTag(ctx, g, fn)
+		})
+	}
+	tag, err := g.GetLatestTag(ctx)
 	if err != nil {
-		return []Commit{}, err
+		return err
+	}
+	if tag == "" {
+		return g.streamCommits(ctx, g.withPaths(g.ref()), fn)
 	}
+	return g.streamCommits(ctx, g.withPaths(fmt.Sprintf("refs/tags/%s..%s", tag, g.ref())), fn)
+}
 
-	commits, err := g.parseRawCommits([]string{fmt.Sprintf("%s..HEAD", tag)})
+// ForEachCommit calls fn with each commit of a revision range, newest first, as git log prints them. The commits are
+// not held in memory, which suits ranges of any size. An error returned by fn stops git and is returned as is.
+func (g Git) ForEachCommit(ctx context.Context, revRange string, fn func(Commit) error) error {
+	return g.streamCommits(ctx, g.withPaths(revRange), fn)
+}
+
+// GetHeadCommit fetches the commit HEAD, or the Ref when set, points at, whatever the Paths are.
+func (g Git) GetHeadCommit(ctx context.Context) (Commit, error) {
+	commits, err := g.parseRawCommits(ctx, []string{"-1", g.ref(), "--"})
 	if err != nil {
-		return []Commit{}, err
+		return Commit{}, err
 	}
-	return commits, nil
+	if len(commits) == 0 {
+		return Commit{}, fmt.Errorf("%s has no commit", g.ref())
+	}
+	return commits[0], nil
+}
+
+// withPaths appends the Paths to the git arguments after a --, which also keeps a revision named like a file, ie. a
+// develop branch next to a develop directory, from being read as a path.
+func (g Git) withPaths(args ...string) []string {
+	return append(append(args, "--"), g.Paths...)
 }
 
 // Add stages a file to be tracked by git.
-func (g Git) Add(file string) error{
-	err := g.exec("add", file).Run()
+func (g Git) Add(ctx context.Context, file string) error{
+	_, err := g.output(ctx, "add", file)
 	if err != nil {
-		return fmt.Errorf("could not stage file err=%v", err)
+		return fmt.Errorf("could not stage file err=%w", err)
 	}
 	return nil
 }
 
 // CreateCommit creates a commit with a passed in message and whether or not to allow it be to empty
-func (g Git) CreateCommit(subject string, body string, allowEmpty bool) (Commit, error) {
+func (g Git) CreateCommit(ctx context.Context, subject string, body string, allowEmpty bool) (Commit, error) {
 	args := []string{"--quiet", "--cleanup", "strip", "-m", subject, "-m", body}
 	if allowEmpty {
 		args = append(args, "--allow-empty")
 	}
-	err := g.exec("commit", args...).Run()
+	_, err := g.output(ctx, "commit", args...)
 	if err != nil {
-		return Commit{}, fmt.Errorf("could not create commit err=%v", err)
+		return Commit{}, fmt.Errorf("could not create commit err=%w", err)
 	}
-	commits, err := g.parseRawCommits([]string{"-n1"})
+	commits, err := g.parseRawCommits(ctx, []string{"-n1"})
 	if err != nil {
 		return Commit{}, err
 	}
@@ -75,46 +129,89 @@ func (g Git) CreateCommit(subject string, body string, allowEmpty bool) (Commit,
 
 // parseRawCommits takes a list of git log arguments and parses each commit from the git log output
 // and converts them to a list of Commit.
-func (g Git) parseRawCommits(args []string) ([]Commit, error) {
-	args = append([]string{fmt.Sprintf(`--format=%s`, commitLogFormat)}, args...)
-	out, err := g.exec("log", args...).Output()
+func (g Git) parseRawCommits(ctx context.Context, args []string) ([]Commit, error) {
+	commits := make([]Commit, 0)
+	err := g.streamCommits(ctx, args, func(c Commit) error {
+		commits = append(commits, c)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return commits, nil
+}
 
-	var b strings.Builder
-	_ , err = b.Write(out)
+// streamCommits runs git log with the arguments and calls fn with each commit as soon as git printed it.
+func (g Git) streamCommits(ctx context.Context, args []string, fn func(Commit) error) error {
+	logCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := g.exec(logCtx, "log", append([]string{"--format=" + commitLogFormat}, args...)...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("could not read git log err=%w", err)
+	}
+	if err = cmd.Start(); err != nil {
+		return commandError(ctx, cmd, err, "")
 	}
 
-	rawCommits := splitAndFilter(b.String(), commitSeparator)
-	commits := make([]Commit, 0)
-	for _, line := range rawCommits {
-		tokens := strings.Split(strings.Trim(line, "\n"), "\n")
-		date, err := time.Parse(time.RFC3339, tokens[0])
-		if err != nil {
-			return nil, err
+	if err = readCommits(bufio.NewReader(stdout), fn); err != nil {
+		// git is killed rather than left blocking on a pipe nobody reads anymore
+		cancel()
+		_ = cmd.Wait()
+		if ctx.Err() != nil {
+			return ErrGitInterrupted{Args: cmd.Args[3:], Err: ctx.Err()}
 		}
-		body := ""
-		if len(tokens) > 5 {
-			for _, s := range tokens[5:] {
-				body += fmt.Sprintln(s)
+		return err
+	}
+	return commandError(ctx, cmd, cmd.Wait(), stderr.String())
+}
+
+// readCommits reads the commits printed in the commitLogFormat and calls fn with each of them.
+func readCommits(r *bufio.Reader, fn func(Commit) error) error {
+	for {
+		fields := make([]string, 0, commitFields)
+		for len(fields) < commitFields {
+			field, err := r.ReadString(0)
+			if err == io.EOF && len(fields) == 0 && strings.TrimSpace(field) == "" {
+				return nil
+			}
+			if err == io.EOF {
+				return fmt.Errorf("truncated commit in git log output fields=%d", len(fields))
+			}
+			if err != nil {
+				return fmt.Errorf("could not read git log output err=%w", err)
 			}
+			fields = append(fields, strings.TrimSuffix(field, "\x00"))
 		}
-		c := Commit{
-			Hash: tokens[1],
-			Author: Author {
-				Name:  tokens[2],
-				Email: tokens[3],
-			},
-			Subject: tokens[4],
-			Body:    strings.TrimSuffix(body, "\n"),
-			Date:    date,
+		c, err := parseCommit(fields)
+		if err != nil {
+			return err
+		}
+		if err = fn(c); err != nil {
+			return err
 		}
-		commits = append(commits, c)
 	}
-	return commits, nil
+}
+
+// parseCommit converts the fields of a commit in the commitLogFormat to a Commit.
+func parseCommit(fields []string) (Commit, error) {
+	// git terminates every commit but the last one with a newline
+	date, err := time.Parse(time.RFC3339, strings.TrimPrefix(fields[0], "\n"))
+	if err != nil {
+		return Commit{}, fmt.Errorf("could not parse commit date err=%w", err)
+	}
+	return Commit{
+		Hash: fields[1],
+		Author: Author{
+			Name:  fields[2],
+			Email: fields[3],
+		},
+		Subject: fields[4],
+		Body:    strings.Trim(fields[5], "\n"),
+		Date:    date,
+	}, nil
 }
 
 // splitAndFilter takes in a string and filters out any empty string or new line
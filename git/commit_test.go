@@ -1,19 +1,27 @@
 package git
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
 
 func (s *CommitTestSuite) TestNoPreviousCommits() {
-	_, err := s.Git.GetCommitsSinceLatestTag()
+	ctx := context.Background()
+	_, err := s.Git.GetCommitsSinceLatestTag(ctx)
 	assert.Error(s.T(), err)
 }
 
 func (s *CommitTestSuite) TestCreateCommit() {
+	ctx := context.Background()
 	//create a commits and a tag
 	tempFile := "foobar"
 	file, err := ioutil.TempFile(s.Git.WorkDirectory, tempFile)
@@ -21,11 +29,11 @@ func (s *CommitTestSuite) TestCreateCommit() {
 		s.Failf("could not create temp file", "error=%v", err)
 	}
 
-	if err = s.Git.Add(file.Name()); err != nil {
+	if err = s.Git.Add(ctx, file.Name()); err != nil {
 		s.Fail("could not stage file", err)
 	}
 
-	c, err := s.Git.CreateCommit("this is my first commit", "", false)
+	c, err := s.Git.CreateCommit(ctx, "this is my first commit", "", false)
 	if err != nil {
 		s.Error(err)
 	}
@@ -34,19 +42,20 @@ func (s *CommitTestSuite) TestCreateCommit() {
 }
 
 func (s *CommitTestSuite) TestGetCommitsSinceLatestTag() {
-	_, err := s.Git.CreateCommit("this is my second commit", "", true)
+	ctx := context.Background()
+	_, err := s.Git.CreateCommit(ctx, "this is my second commit", "", true)
 	if err != nil {
 		s.Error(err, "could not create second commit")
 	}
-	err = s.Git.CreateTag("v0.0.1", false)
+	err = s.Git.CreateTag(ctx, "v0.0.1", false)
 	if err != nil {
 		s.Error(err, "could not create tag")
 	}
-	c1, _ := s.Git.CreateCommit("this is my third commit", "\nthis is the body\n\nlalala", true)
-	c2, _ := s.Git.CreateCommit("this is my fourth commit", "", true)
-	c3, _ := s.Git.CreateCommit("this is my fifth commit", "", true)
+	c1, _ := s.Git.CreateCommit(ctx, "this is my third commit", "\nthis is the body\n\nlalala", true)
+	c2, _ := s.Git.CreateCommit(ctx, "this is my fourth commit", "", true)
+	c3, _ := s.Git.CreateCommit(ctx, "this is my fifth commit", "", true)
 
-	commits, err := s.Git.GetCommitsSinceLatestTag()
+	commits, err := s.Git.GetCommitsSinceLatestTag(ctx)
 	if err != nil {
 		s.Error(err, "could not get latest ")
 	}
@@ -55,6 +64,151 @@ func (s *CommitTestSuite) TestGetCommitsSinceLatestTag() {
 	s.ElementsMatch(commits, []Commit{c1, c2, c3})
 }
 
+func (s *CommitTestSuite) TestForEachCommitSinceLatestTag() {
+	ctx := context.Background()
+	_, _ = s.Git.CreateCommit(ctx, "feat: first", "", true)
+	if err := s.Git.CreateTag(ctx, "v1.0.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	_, _ = s.Git.CreateCommit(ctx, "fix: second", "", true)
+	_, _ = s.Git.CreateCommit(ctx, "fix: third", "", true)
+
+	stop := errors.New("stop")
+	for _, backend := range []string{BackendExec, BackendNative} {
+		var subjects []string
+		err := s.Git.WithBackend(backend).ForEachCommitSinceLatestTag(ctx, func(c Commit) error {
+			subjects = append(subjects, c.Subject)
+			return nil
+		})
+		s.NoError(err, backend)
+		s.Equal([]string{"fix: third", "fix: second"}, subjects, backend)
+
+		subjects = nil
+		err = s.Git.WithBackend(backend).ForEachCommitSinceLatestTag(ctx, func(c Commit) error {
+			subjects = append(subjects, c.Subject)
+			return stop
+		})
+		s.ErrorIs(err, stop, backend)
+		s.Equal([]string{"fix: third"}, subjects, backend)
+	}
+}
+
+func (s *CommitTestSuite) TestGetCommitsSinceLatestTagWithoutTags() {
+	ctx := context.Background()
+	c1, _ := s.Git.CreateCommit(ctx, "this is my first commit", "", true)
+	c2, _ := s.Git.CreateCommit(ctx, "this is my second commit", "", true)
+
+	commits, err := s.Git.GetCommitsSinceLatestTag(ctx)
+	if err != nil {
+		s.Error(err, "could not get commits")
+	}
+	s.ElementsMatch(commits, []Commit{c1, c2})
+}
+
+func (s *CommitTestSuite) TestGetCommitsSinceLatestTagWithPaths() {
+	ctx := context.Background()
+	_, _ = s.Git.CreateCommit(ctx, "this is my first commit", "", true)
+	if err := s.Git.CreateTag(ctx, "lib/v0.0.1", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if err := os.MkdirAll(filepath.Join(s.Git.WorkDirectory, "lib"), 0755); err != nil {
+		s.FailNow("could not create directory", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.Git.WorkDirectory, "lib", "lib.go"), []byte("package lib"), 0644); err != nil {
+		s.FailNow("could not create file", err)
+	}
+	if err := s.Git.Add(ctx, "lib"); err != nil {
+		s.FailNow("could not stage file", err)
+	}
+	c1, _ := s.Git.CreateCommit(ctx, "feat: touches lib", "", false)
+	_, _ = s.Git.CreateCommit(ctx, "feat: touches nothing", "", true)
+
+	commits, err := s.Git.WithScope(Scope{TagPrefix: "lib/", Paths: []string{"lib"}}).GetCommitsSinceLatestTag(ctx)
+	if err != nil {
+		s.FailNow("could not get commits", err)
+	}
+	s.Equal([]Commit{c1}, commits)
+}
+
+func (s *CommitTestSuite) TestGetCommitsSeparators() {
+	ctx := context.Background()
+	c1, _ := s.Git.CreateCommit(ctx, "feat: split ~~ on tildes", "a body ~~ with tildes\n\n~~\n\nand paragraphs", true)
+	c2, _ := s.Git.CreateCommit(ctx, "fix: no body", "", true)
+
+	commits, err := s.Git.GetCommitsSinceLatestTag(ctx)
+	s.NoError(err)
+	s.Equal([]Commit{c2, c1}, commits)
+	s.Equal("feat: split ~~ on tildes", c1.Subject)
+	s.Equal("a body ~~ with tildes\n\n~~\n\nand paragraphs", c1.Body)
+	s.Equal("", c2.Body)
+}
+
+func (s *CommitTestSuite) TestForEachCommit() {
+	ctx := context.Background()
+	var first Commit
+	for _, subject := range []string{"first commit", "second commit", "third commit"} {
+		c, err := s.Git.CreateCommit(ctx, subject, "", true)
+		if err != nil {
+			s.FailNow("could not create commit", err)
+		}
+		if first.Hash == "" {
+			first = c
+		}
+	}
+
+	var subjects []string
+	err := s.Git.ForEachCommit(ctx, "HEAD", func(c Commit) error {
+		subjects = append(subjects, c.Subject)
+		return nil
+	})
+	s.NoError(err)
+	s.Equal([]string{"third commit", "second commit", "first commit"}, subjects)
+
+	subjects = nil
+	err = s.Git.ForEachCommit(ctx, first.Hash+"..HEAD", func(c Commit) error {
+		subjects = append(subjects, c.Subject)
+		return nil
+	})
+	s.NoError(err)
+	s.Equal([]string{"third commit", "second commit"}, subjects)
+
+	stop := errors.New("stop")
+	subjects = nil
+	err = s.Git.ForEachCommit(ctx, "HEAD", func(c Commit) error {
+		subjects = append(subjects, c.Subject)
+		return stop
+	})
+	s.ErrorIs(err, stop)
+	s.Equal([]string{"third commit"}, subjects)
+
+	err = s.Git.ForEachCommit(ctx, "unknown..HEAD", func(c Commit) error { return nil })
+	var failed ErrGitFailed
+	s.ErrorAs(err, &failed)
+}
+
+func TestReadCommits(t *testing.T) {
+	out := "2021-06-01T10:00:00+02:00\x00abc\x00megatron\x00megatron@email.com\x00feat: a\x00body\n\x00\n" +
+		"2021-05-01T10:00:00+02:00\x00def\x00megatron\x00megatron@email.com\x00fix: b\x00\x00\n"
+	var commits []Commit
+	err := readCommits(bufio.NewReader(strings.NewReader(out)), func(c Commit) error {
+		commits = append(commits, c)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, commits, 2) {
+		assert.Equal(t, "abc", commits[0].Hash)
+		assert.Equal(t, "body", commits[0].Body)
+		assert.Equal(t, "def", commits[1].Hash)
+		assert.Equal(t, "fix: b", commits[1].Subject)
+		assert.Equal(t, 2021, commits[1].Date.Year())
+	}
+
+	err = readCommits(bufio.NewReader(strings.NewReader("2021-06-01T10:00:00+02:00\x00abc\x00")), func(Commit) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
+
 func TestCommitTestSuite(t *testing.T) {
 	suite.Run(t, new(CommitTestSuite))
 }
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrNotARepository is returned when the working directory is not inside a git repository.
+var ErrNotARepository = errors.New("not a git repository")
+
+// ErrNoTags is returned when a version must be based on the latest tag but the repository has none.
+var ErrNoTags = errors.New("no tags")
+
+// ErrGitFailed is returned when a git command exits with an error. It carries what git printed on stderr, which is
+// usually the only explanation of the failure.
+type ErrGitFailed struct {
+	// Args are the arguments of the git command, ie. [describe --tags]
+	Args     []string
+	Stderr   string
+	ExitCode int
+}
+
+func (e ErrGitFailed) Error() string {
+	return fmt.Sprintf("git %s failed exit=%d stderr=%s", strings.Join(e.Args, " "), e.ExitCode, e.Stderr)
+}
+
+// Is matches ErrNotARepository when git failed because the working directory is not inside a repository or does not
+// exist.
+func (e ErrGitFailed) Is(target error) bool {
+	return target == ErrNotARepository &&
+		(strings.Contains(e.Stderr, "not a git repository") || strings.Contains(e.Stderr, "cannot change to"))
+}
+
+// ErrGitInterrupted is returned when a git command is killed because the context of the operation is done. It unwraps
+// to the error of the context, a timeout is matched with errors.Is(err, context.DeadlineExceeded).
+type ErrGitInterrupted struct {
+	// Args are the arguments of the git command, ie. [log --format=...]
+	Args []string
+	Err  error
+}
+
+func (e ErrGitInterrupted) Error() string {
+	return fmt.Sprintf("git %s interrupted err=%v", strings.Join(e.Args, " "), e.Err)
+}
+
+func (e ErrGitInterrupted) Unwrap() error {
+	return e.Err
+}
+
+// run runs the git command created with the ctx and returns its standard output. A command exiting with an error
+// returns an ErrGitFailed with the stderr of git, a command killed because the ctx is done an ErrGitInterrupted.
+func run(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	return out, commandError(ctx, cmd, err, stderr.String())
+}
+
+// commandError converts the error of running the git command created with the ctx, see run.
+func commandError(ctx context.Context, cmd *exec.Cmd, err error, stderr string) error {
+	if err == nil {
+		return nil
+	}
+	// the arguments start after git -C <WorkDirectory>
+	if ctx.Err() != nil {
+		return ErrGitInterrupted{Args: cmd.Args[3:], Err: ctx.Err()}
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return ErrGitFailed{Args: cmd.Args[3:], Stderr: strings.TrimSpace(stderr), ExitCode: exitErr.ExitCode()}
+	}
+	return fmt.Errorf("could not run git err=%w", err)
+}
@@ -1,12 +1,29 @@
 package git
 
 import (
-	"log"
+	"context"
 	"os/exec"
+	"strings"
+)
+
+// Backends answering the tag and commit queries
+const (
+	// BackendExec runs the git binary, the default.
+	BackendExec = "exec"
+	// BackendNative reads the refs and objects of the repository in-process, see native.go.
+	BackendNative = "native"
 )
 
 type Git struct {
 	WorkDirectory string
+	// Backend answers GetLatestTag, GetLatestPreReleaseTag, Describe, ListTags and GetCommitsSinceLatestTag, one of
+	// the backend constants. Empty is BackendExec. Every other method runs the git binary.
+	Backend string
+	// Scope restricts the tags and commits looked up, see WithScope. The zero Scope looks up every tag and commit.
+	Scope
+	// Ref is the revision the tag and commit queries look up instead of HEAD, ie. a branch, a tag or a commit hash.
+	// New tags point at it too. Empty is HEAD.
+	Ref string
 }
 
 func New(workDir string) Git {
@@ -15,18 +32,59 @@ func New(workDir string) Git {
 	}
 }
 
-// IsValidGitDir checks if the current working directory contains a git repository.
-func (g Git) IsValidGitDir() bool {
-	cmd := g.exec("rev-parse", "--git-dir")
-	if err := cmd.Run(); err != nil {
-		log.Printf("[ERROR] Invalid git repository. error=%v", err)
-		return false
+// WithBackend returns a copy of the Git which answers the tag and commit queries with the backend.
+func (g Git) WithBackend(backend string) Git {
+	g.Backend = backend
+	return g
+}
+
+// WithRef returns a copy of the Git which looks up the tags and commits of the revision instead of HEAD.
+func (g Git) WithRef(ref string) Git {
+	g.Ref = ref
+	return g
+}
+
+// ref returns the revision the queries look up, HEAD unless the Ref is set.
+func (g Git) ref() string {
+	if g.Ref == "" {
+		return "HEAD"
+	}
+	return g.Ref
+}
+
+// TopLevel returns the absolute path of the root directory of the repository.
+func (g Git) TopLevel(ctx context.Context) (string, error) {
+	out, err := g.output(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// IsValidGitDir checks if the current working directory contains a git repository. It is false as well when the ctx is
+// done before git answers, see GitDir for the reason.
+func (g Git) IsValidGitDir(ctx context.Context) bool {
+	_, err := g.GitDir(ctx)
+	return err == nil
+}
+
+// GitDir returns the path of the git directory of the repository the WorkDirectory is in. Outside of a repository it
+// returns an ErrGitFailed matching ErrNotARepository.
+func (g Git) GitDir(ctx context.Context) (string, error) {
+	out, err := g.output(ctx, "rev-parse", "--git-dir")
+	if err != nil {
+		return "", err
 	}
-	return true
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// output runs the git command with the targeted WorkDirectory and returns its standard output, see run.
+func (g Git) output(ctx context.Context, action string, args ...string) ([]byte, error) {
+	return run(ctx, g.exec(ctx, action, args...))
 }
 
-// exec runs the underlying git command with the targeted WorkDirectory.
-func (g Git) exec(action string, args... string) *exec.Cmd{
+// exec runs the underlying git command with the targeted WorkDirectory. The git process is killed when the ctx is done.
+func (g Git) exec(ctx context.Context, action string, args... string) *exec.Cmd{
 	args = append([]string{"-C", g.WorkDirectory, action}, args...)
-	return exec.Command("git", args...)
+	return exec.CommandContext(ctx, "git", args...)
 }
@@ -1,21 +1,70 @@
 package git
 
 import (
+	"context"
 	"github.com/stretchr/testify/suite"
 	"os"
 	"os/exec"
 	"testing"
+	"time"
 )
 
 func (s GitTestSuite) TestIsValidGitDir() {
-	s.True(s.Git.IsValidGitDir())
+	ctx := context.Background()
+	s.True(s.Git.IsValidGitDir(ctx))
 
 	tempDir, err := os.MkdirTemp("", "")
 	if err != nil {
 		s.FailNow("could not create temporary directory", err)
 	}
 	g := New(tempDir)
-	s.False(g.IsValidGitDir())
+	s.False(g.IsValidGitDir(ctx))
+	_, err = g.GitDir(ctx)
+	s.ErrorIs(err, ErrNotARepository)
+	s.ErrorAs(err, new(ErrGitFailed))
+}
+
+func (s GitTestSuite) TestErrGitFailed() {
+	ctx := context.Background()
+	_, err := s.Git.output(ctx, "rev-parse", "--verify", "missing")
+	var failed ErrGitFailed
+	s.ErrorAs(err, &failed)
+	s.Equal([]string{"rev-parse", "--verify", "missing"}, failed.Args)
+	s.Equal(128, failed.ExitCode)
+	s.Contains(failed.Stderr, "Needed a single revision")
+	s.NotErrorIs(err, ErrNotARepository)
+
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		s.FailNow("could not create temporary directory", err)
+	}
+	defer os.RemoveAll(tempDir)
+	_, err = New(tempDir).TopLevel(ctx)
+	s.ErrorIs(err, ErrNotARepository)
+}
+
+func (s GitTestSuite) TestErrGitInterrupted() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	// git hangs reading a stdin which is never closed
+	stdin, w, err := os.Pipe()
+	if err != nil {
+		s.FailNow("could not create pipe", err)
+	}
+	defer w.Close()
+	cmd := s.Git.exec(ctx, "hash-object", "--stdin")
+	cmd.Stdin = stdin
+
+	_, err = run(ctx, cmd)
+	var interrupted ErrGitInterrupted
+	s.ErrorAs(err, &interrupted)
+	s.Equal([]string{"hash-object", "--stdin"}, interrupted.Args)
+	s.ErrorIs(err, context.DeadlineExceeded)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = s.Git.GetLatestTag(cancelled)
+	s.ErrorIs(err, context.Canceled)
 }
 
 func TestRunGit(t *testing.T) {
@@ -0,0 +1,390 @@
+package git
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// memoryAuthor is the author of the commits of a Memory repository.
+var memoryAuthor = Author{Name: "megatron", Email: "megatron@email.com"}
+
+// memoryEpoch is the date of the first commit of a Memory repository, every next commit is a minute later.
+var memoryEpoch = time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+// Memory is a Repository held in memory, a commit graph with branches and tags described in code like it would be with
+// git commands, ie.
+//
+//	m := git.NewMemory()
+//	_, _ = m.CreateCommit(ctx, "feat: first", "", true)
+//	_ = m.CreateTag(ctx, "v1.0.0", false)
+//	_, _ = m.CreateCommit(ctx, "fix: second", "", true)
+//
+// The commits have deterministic hashes and dates. Files only exist as the paths staged with Add and committed, they
+// are matched against the Paths of the Scope. Staged files which are not committed yet make the working tree dirty.
+// Copies of a Memory, ie. WithScope, share the same graph. A Memory is not safe for concurrent use.
+type Memory struct {
+	graph *memoryGraph
+	scope Scope
+}
+
+type memoryGraph struct {
+	commits  map[string]*memoryCommit
+	tags     map[string]string
+	branches map[string]string
+	// branch is the checked out branch, empty when HEAD is detached at the detached commit.
+	branch   string
+	detached string
+	staged   []string
+}
+
+type memoryCommit struct {
+	commit  Commit
+	parents []string
+	// order is the position of the commit in the order of creation.
+	order int
+	files []string
+}
+
+// NewMemory returns an empty Memory repository with the main branch checked out.
+func NewMemory() Memory {
+	return Memory{graph: &memoryGraph{
+		commits:  make(map[string]*memoryCommit),
+		tags:     make(map[string]string),
+		branches: make(map[string]string),
+		branch:   "main",
+	}}
+}
+
+// WithScope returns a copy of the Memory which only looks up the tags and commits of the scope.
+func (m Memory) WithScope(scope Scope) Repository {
+	m.scope = scope
+	return m
+}
+
+// Add stages the file, the next commit touches it.
+func (m Memory) Add(_ context.Context, file string) error {
+	m.graph.staged = append(m.graph.staged, strings.TrimPrefix(file, "./"))
+	return nil
+}
+
+// CreateCommit commits the staged files on top of HEAD. Without staged files allowEmpty must be set.
+func (m Memory) CreateCommit(_ context.Context, subject string, body string, allowEmpty bool) (Commit, error) {
+	if len(m.graph.staged) == 0 && !allowEmpty {
+		return Commit{}, fmt.Errorf("could not create commit=%s err=nothing to commit", subject)
+	}
+	var parents []string
+	if head, ok := m.graph.head(); ok {
+		parents = append(parents, head)
+	}
+	return m.graph.commit(subject, body, parents), nil
+}
+
+// Merge commits a merge of the rev into HEAD, ie. a branch name.
+func (m Memory) Merge(_ context.Context, rev string, subject string) (Commit, error) {
+	head, ok := m.graph.head()
+	if !ok {
+		return Commit{}, fmt.Errorf("could not merge rev=%s err=HEAD has no commit yet", rev)
+	}
+	other, err := m.graph.resolve(rev)
+	if err != nil {
+		return Commit{}, fmt.Errorf("could not merge rev=%s err=%w", rev, err)
+	}
+	return m.graph.commit(subject, "", []string{head, other}), nil
+}
+
+// CreateBranch creates the branch at HEAD and checks it out, like git checkout -b does.
+func (m Memory) CreateBranch(_ context.Context, name string) error {
+	if _, ok := m.graph.branches[name]; ok || name == m.graph.branch {
+		return fmt.Errorf("could not create branch=%s err=it already exists", name)
+	}
+	head, ok := m.graph.head()
+	if !ok {
+		return fmt.Errorf("could not create branch=%s err=HEAD has no commit yet", name)
+	}
+	m.graph.branches[name] = head
+	m.graph.branch = name
+	return nil
+}
+
+// Checkout checks out the rev, a branch name, a tag or a commit hash. HEAD is detached unless rev is a branch.
+func (m Memory) Checkout(_ context.Context, rev string) error {
+	if _, ok := m.graph.branches[rev]; ok {
+		m.graph.branch, m.graph.detached = rev, ""
+		return nil
+	}
+	id, err := m.graph.resolve(rev)
+	if err != nil {
+		return fmt.Errorf("could not checkout rev=%s err=%w", rev, err)
+	}
+	m.graph.branch, m.graph.detached = "", id
+	return nil
+}
+
+// CreateTag tags HEAD. The annotated flag makes no difference in memory, it is there to mirror Git.CreateTag.
+func (m Memory) CreateTag(ctx context.Context, tag string, _ bool) error {
+	return m.CreateAnnotatedTag(ctx, tag, tag)
+}
+
+// CreateAnnotatedTag tags HEAD, the message is not kept.
+func (m Memory) CreateAnnotatedTag(_ context.Context, tag string, _ string) error {
+	if _, ok := m.graph.tags[tag]; ok {
+		return fmt.Errorf("could not create git tag=%s err=it already exists", tag)
+	}
+	head, ok := m.graph.head()
+	if !ok {
+		return fmt.Errorf("could not create git tag=%s err=HEAD has no commit yet", tag)
+	}
+	m.graph.tags[tag] = head
+	return nil
+}
+
+// ListTags lists the tags matching the glob pattern like git tag --list does, sorted by name.
+func (m Memory) ListTags(_ context.Context, pattern string) ([]string, error) {
+	match, err := globMatcher(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for t := range m.graph.tags {
+		if match(t) {
+			tags = append(tags, t)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// GetLatestTag returns the tag of the highest semantic version reachable from HEAD, see Git.GetLatestTag.
+func (m Memory) GetLatestTag(_ context.Context) (string, error) {
+	head, ok := m.graph.head()
+	if !ok {
+		return "", nil
+	}
+	reachable := m.graph.ancestors(head)
+	var merged []string
+	for t, id := range m.graph.tags {
+		if reachable[id] {
+			merged = append(merged, t)
+		}
+	}
+	return m.scope.latestOf(merged), nil
+}
+
+// GetLatestPreReleaseTag describes HEAD relative to the latest tag, see Git.GetLatestPreReleaseTag.
+func (m Memory) GetLatestPreReleaseTag(ctx context.Context) (string, error) {
+	d, err := m.Describe(ctx)
+	if err != nil || d.Tag == "" {
+		return "", err
+	}
+	if d.Distance == 0 {
+		return d.Tag, nil
+	}
+	return fmt.Sprintf("%s-%d-%s", d.Tag, d.Distance, d.Hash), nil
+}
+
+// Describe locates HEAD relative to the latest tag, see Git.Describe. The hash is abbreviated to 7 characters.
+func (m Memory) Describe(ctx context.Context) (Description, error) {
+	tag, err := m.GetLatestTag(ctx)
+	if err != nil {
+		return Description{}, err
+	}
+	commits, err := m.commitsSince(tag)
+	if err != nil {
+		return Description{}, fmt.Errorf("could not count commits since tag=%s err=%w", tag, err)
+	}
+	head, _ := m.graph.head()
+	return Description{Tag: tag, Distance: len(commits), Hash: head[:shortHashLength]}, nil
+}
+
+// GetCommitsSinceLatestTag returns the commits reachable from HEAD since the latest tag, newest first. Only the commits
+// touching the Paths of the scope are returned when set, merge commits touch none.
+func (m Memory) GetCommitsSinceLatestTag(ctx context.Context) ([]Commit, error) {
+	filtered := make([]Commit, 0)
+	err := m.ForEachCommitSinceLatestTag(ctx, func(c Commit) error {
+		filtered = append(filtered, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// ForEachCommitSinceLatestTag calls fn with each commit GetCommitsSinceLatestTag returns, newest first. An error
+// returned by fn stops the walk and is returned as is.
+func (m Memory) ForEachCommitSinceLatestTag(ctx context.Context, fn func(Commit) error) error {
+	tag, err := m.GetLatestTag(ctx)
+	if err != nil {
+		return err
+	}
+	commits, err := m.commitsSince(tag)
+	if err != nil {
+		return err
+	}
+	for _, c := range commits {
+		if len(m.scope.Paths) > 0 && !m.touches(c.files) {
+			continue
+		}
+		if err = fn(c.commit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHeadCommit returns the commit HEAD points at.
+func (m Memory) GetHeadCommit(_ context.Context) (Commit, error) {
+	head, ok := m.graph.head()
+	if !ok {
+		return Commit{}, fmt.Errorf("could not fetch HEAD commit err=HEAD has no commit yet")
+	}
+	return m.graph.commits[head].commit, nil
+}
+
+// IsDirty reports whether files are staged but not committed yet. Only the files touching the Paths count when set.
+func (m Memory) IsDirty(_ context.Context) (bool, error) {
+	return len(m.stagedInScope()) > 0, nil
+}
+
+// DiffHash returns a short hash of the names of the staged files. Only the files touching the Paths count when set.
+func (m Memory) DiffHash(_ context.Context) (string, error) {
+	h := sha1.New()
+	h.Write([]byte(strings.Join(m.stagedInScope(), "\n")))
+	return fmt.Sprintf("%x", h.Sum(nil))[:shortHashLength], nil
+}
+
+// stagedInScope returns the staged files touching the Paths, every staged file without Paths.
+func (m Memory) stagedInScope() []string {
+	var files []string
+	for _, f := range m.graph.staged {
+		if len(m.scope.Paths) == 0 || m.touches([]string{f}) {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// commitsSince returns the commits reachable from HEAD but not from the tag, every commit of HEAD when the tag is
+// empty, newest first.
+func (m Memory) commitsSince(tag string) ([]*memoryCommit, error) {
+	head, ok := m.graph.head()
+	if !ok {
+		return nil, fmt.Errorf("HEAD has no commit yet")
+	}
+	excluded := make(map[string]bool)
+	if tag != "" {
+		excluded = m.graph.ancestors(m.graph.tags[tag])
+	}
+	var commits []*memoryCommit
+	for id := range m.graph.ancestors(head) {
+		if !excluded[id] {
+			commits = append(commits, m.graph.commits[id])
+		}
+	}
+	// the dates grow with the order of creation, git log lists the newest first
+	sort.Slice(commits, func(i, j int) bool { return commits[i].order > commits[j].order })
+	return commits, nil
+}
+
+// touches reports whether any of the files matches the Paths of the scope. A pathspec matches the file itself and
+// the files of the directory it names, "." every file. Pathspecs starting with :(exclude) exclude the files they match.
+func (m Memory) touches(files []string) bool {
+	for _, f := range files {
+		included, excluded := false, false
+		for _, p := range m.scope.Paths {
+			if spec := strings.TrimPrefix(p, ":(exclude)"); spec != p {
+				excluded = excluded || matchPathspec(spec, f)
+			} else {
+				included = included || matchPathspec(p, f)
+			}
+		}
+		if included && !excluded {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathspec reports whether the file is the path of the spec or in its directory.
+func matchPathspec(spec string, file string) bool {
+	spec = strings.TrimSuffix(strings.TrimPrefix(spec, "./"), "/")
+	return spec == "." || spec == "" || file == spec || strings.HasPrefix(file, spec+"/")
+}
+
+// head returns the commit HEAD points at, false before the first commit of the branch.
+func (g *memoryGraph) head() (string, bool) {
+	if g.branch == "" {
+		return g.detached, true
+	}
+	id, ok := g.branches[g.branch]
+	return id, ok
+}
+
+// resolve returns the commit of a branch, a tag or a commit hash, which may be abbreviated.
+func (g *memoryGraph) resolve(rev string) (string, error) {
+	if id, ok := g.branches[rev]; ok {
+		return id, nil
+	}
+	if id, ok := g.tags[rev]; ok {
+		return id, nil
+	}
+	var found []string
+	for id := range g.commits {
+		if len(rev) >= 4 && strings.HasPrefix(id, rev) {
+			found = append(found, id)
+		}
+	}
+	if len(found) != 1 {
+		return "", fmt.Errorf("unknown revision=%s", rev)
+	}
+	return found[0], nil
+}
+
+// commit creates a commit of the staged files with the parents and moves HEAD to it.
+func (g *memoryGraph) commit(subject string, body string, parents []string) Commit {
+	order := len(g.commits)
+	// the message reads back like git log prints it
+	subject, body = splitMessage(subject + "\n\n" + body)
+	h := sha1.New()
+	_, _ = fmt.Fprintf(h, "%d\n%s\n%s\n\n%s", order, strings.Join(parents, " "), subject, body)
+	c := &memoryCommit{
+		commit: Commit{
+			Subject: subject,
+			Body:    body,
+			Hash:    fmt.Sprintf("%x", h.Sum(nil)),
+			Author:  memoryAuthor,
+			Date:    memoryEpoch.Add(time.Duration(order) * time.Minute),
+		},
+		parents: parents,
+		order:   order,
+		files:   g.staged,
+	}
+	g.commits[c.commit.Hash] = c
+	g.staged = nil
+	if g.branch == "" {
+		g.detached = c.commit.Hash
+	} else {
+		g.branches[g.branch] = c.commit.Hash
+	}
+	return c.commit
+}
+
+// ancestors returns the commit id and every commit reachable from it.
+func (g *memoryGraph) ancestors(id string) map[string]bool {
+	reachable := make(map[string]bool)
+	stack := []string{id}
+	for len(stack) > 0 {
+		id, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		if reachable[id] {
+			continue
+		}
+		reachable[id] = true
+		stack = append(stack, g.commits[id].parents...)
+	}
+	return reachable
+}
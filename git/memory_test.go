@@ -0,0 +1,182 @@
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func (s *MemoryTestSuite) TestMemoryLikeGit() {
+	ctx := context.Background()
+	m := NewMemory()
+	commit := func(subject string, files ...string) {
+		for _, f := range files {
+			file := filepath.Join(s.Git.WorkDirectory, f)
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				s.FailNow("could not create directory", err)
+			}
+			if err := os.WriteFile(file, []byte(subject), 0644); err != nil {
+				s.FailNow("could not write file", err)
+			}
+			_ = s.Git.Add(ctx, f)
+			_ = m.Add(ctx, f)
+		}
+		_, err := s.Git.CreateCommit(ctx, subject, "the body", true)
+		s.NoError(err)
+		_, err = m.CreateCommit(ctx, subject, "the body", true)
+		s.NoError(err)
+	}
+	tag := func(name string) {
+		s.NoError(s.Git.CreateTag(ctx, name, false))
+		s.NoError(m.CreateTag(ctx, name, false))
+	}
+	checkout := func(args ...string) {
+		s.git(append([]string{"checkout", "--quiet"}, args...)...)
+		if args[0] == "-b" {
+			s.NoError(m.CreateBranch(ctx, args[1]))
+		} else {
+			s.NoError(m.Checkout(ctx, args[0]))
+		}
+	}
+
+	commit("feat: first", "file.txt")
+	// the branch git checks out first depends on its configuration
+	checkout("-b", "trunk")
+	tag("v1.0.0")
+	tag("deploy-prod")
+	commit("fix: second", "file.txt")
+	tag("v1.1.0-rc.1")
+	checkout("-b", "feature")
+	commit("feat(lib): add the lib", "lib/lib.go")
+	tag("lib/v0.1.0")
+	commit("fix(lib): fix the lib", "lib/lib.go", "feature.txt")
+	checkout("trunk")
+	commit("fix: on trunk", "file.txt")
+	s.git("merge", "--quiet", "--no-ff", "-m", "Merge branch 'feature'", "feature")
+	_, err := m.Merge(ctx, "feature", "Merge branch 'feature'")
+	s.NoError(err)
+	commit("chore: after the merge")
+	tag("v1.2.0")
+	commit("feat: after the release", "file.txt")
+	commit("feat!: breaking\n\nover two paragraphs")
+
+	scopes := []Scope{
+		{},
+		{ExcludePrereleases: true},
+		{TagPrefix: "lib/", Paths: []string{"lib"}},
+		{Paths: []string{".", ":(exclude)lib"}},
+	}
+	for _, scope := range scopes {
+		s.assertSameAnswers(scope, s.Git.WithScope(scope), m.WithScope(scope))
+	}
+
+	// a detached HEAD in the middle of the history
+	checkout("v1.1.0-rc.1")
+	for _, scope := range scopes {
+		s.assertSameAnswers(scope, s.Git.WithScope(scope), m.WithScope(scope))
+	}
+}
+
+// assertSameAnswers asserts the Memory answers the tag and commit queries like the Git, the hashes aside.
+func (s *MemoryTestSuite) assertSameAnswers(scope Scope, g Repository, m Repository) {
+	ctx := context.Background()
+	tags, err := g.ListTags(ctx, scope.TagPrefix+"*")
+	s.NoError(err)
+	memoryTags, err := m.ListTags(ctx, scope.TagPrefix+"*")
+	s.NoError(err)
+	s.Equal(tags, memoryTags, scope)
+
+	d, err := g.Describe(ctx)
+	s.NoError(err)
+	memoryDescription, err := m.Describe(ctx)
+	s.NoError(err)
+	s.Equal(d.Tag, memoryDescription.Tag, scope)
+	s.Equal(d.Distance, memoryDescription.Distance, scope)
+
+	commits, err := g.GetCommitsSinceLatestTag(ctx)
+	s.NoError(err)
+	memoryCommits, err := m.GetCommitsSinceLatestTag(ctx)
+	s.NoError(err)
+	subjects := func(commits []Commit) (subjects []string) {
+		for _, c := range commits {
+			subjects = append(subjects, c.Subject+"\n"+c.Body)
+		}
+		return subjects
+	}
+	s.Equal(subjects(commits), subjects(memoryCommits), scope)
+}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	tag, err := m.GetLatestTag(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "", tag)
+	_, err = m.GetHeadCommit(ctx)
+	assert.Error(t, err, "HEAD has no commit yet")
+	assert.Error(t, m.CreateTag(ctx, "v1.0.0", false), "HEAD has no commit yet")
+	_, err = m.CreateCommit(ctx, "feat: nothing", "", false)
+	assert.Error(t, err, "nothing to commit")
+
+	first, err := m.CreateCommit(ctx, "feat: first", "", true)
+	assert.NoError(t, err)
+	assert.NoError(t, m.CreateTag(ctx, "v1.0.0", false))
+	assert.Error(t, m.CreateTag(ctx, "v1.0.0", false), "the tag exists")
+	second, err := m.CreateCommit(ctx, "fix: second", "", true)
+	assert.NoError(t, err)
+	assert.True(t, second.Date.After(first.Date))
+	assert.Equal(t, memoryAuthor, second.Author)
+
+	d, err := m.Describe(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Description{Tag: "v1.0.0", Distance: 1, Hash: second.ShortHash()}, d)
+	head, err := m.GetHeadCommit(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, second, head)
+
+	// the same history has the same hashes
+	again := NewMemory()
+	_, _ = again.CreateCommit(ctx, "feat: first", "", true)
+	c, _ := again.CreateCommit(ctx, "fix: second", "", true)
+	assert.Equal(t, second.Hash, c.Hash)
+
+	// the working tree is dirty while files are staged
+	dirty, err := m.IsDirty(ctx)
+	assert.NoError(t, err)
+	assert.False(t, dirty)
+	assert.NoError(t, m.Add(ctx, "lib/lib.go"))
+	dirty, _ = m.IsDirty(ctx)
+	assert.True(t, dirty)
+	dirty, _ = m.WithScope(Scope{Paths: []string{"cmd"}}).IsDirty(ctx)
+	assert.False(t, dirty)
+	hash, err := m.DiffHash(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, hash, shortHashLength)
+
+	assert.NoError(t, m.Checkout(ctx, first.ShortHash()))
+	tag, _ = m.GetLatestTag(ctx)
+	assert.Equal(t, "v1.0.0", tag)
+	assert.Error(t, m.Checkout(ctx, "unknown"))
+	_, err = m.Merge(ctx, "unknown", "Merge branch 'unknown'")
+	assert.Error(t, err)
+}
+
+func TestMemoryTestSuite(t *testing.T) {
+	suite.Run(t, new(MemoryTestSuite))
+}
+
+type MemoryTestSuite struct {
+	GitTestSuite
+}
+
+// git runs a git command in the repository.
+func (s *MemoryTestSuite) git(args ...string) {
+	out, err := exec.Command("git", append([]string{"-C", s.Git.WorkDirectory}, args...)...).CombinedOutput()
+	if err != nil {
+		s.FailNow("git failed", "args=%v err=%v out=%s", args, err, out)
+	}
+}
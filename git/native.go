@@ -0,0 +1,442 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// errUnknownRevision is returned for refs which don't exist.
+var errUnknownRevision = errors.New("unknown revision")
+
+// repository reads the refs and objects of a repository straight from its git directory for the BackendNative. It
+// supports SHA-1 repositories with loose and packed refs and objects, alternates, worktrees and shallow clones.
+type repository struct {
+	// gitDir holds the HEAD of the worktree, commonDir the refs and objects shared by the worktrees.
+	gitDir    string
+	commonDir string
+	objects   *objectStore
+	// shallow are the commits of a shallow clone whose parents were not fetched.
+	shallow    map[objectID]bool
+	packedRefs map[string]string
+}
+
+// native opens the repository of the WorkDirectory and runs fn with it.
+func (g Git) native(fn func(r *repository) error) error {
+	r, err := openRepository(g.WorkDirectory)
+	if err != nil {
+		return err
+	}
+	defer r.close()
+	return fn(r)
+}
+
+// openRepository opens the repository of the working directory, which is the first directory up from it with a .git.
+func openRepository(workDir string) (*repository, error) {
+	dir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		fi, err := os.Stat(dotGit)
+		if err == nil {
+			return openGitDir(dotGit, fi.IsDir())
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("%w: %s", ErrNotARepository, workDir)
+		}
+		dir = parent
+	}
+}
+
+// openGitDir opens the repository of a .git directory, or of a .git file pointing to the git directory of a worktree
+// or submodule.
+func openGitDir(dotGit string, isDir bool) (*repository, error) {
+	r := &repository{gitDir: dotGit}
+	if !isDir {
+		b, err := os.ReadFile(dotGit)
+		if err != nil {
+			return nil, fmt.Errorf("could not read git file err=%w", err)
+		}
+		gitDir := strings.TrimSpace(strings.TrimPrefix(string(b), "gitdir:"))
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(filepath.Dir(dotGit), gitDir)
+		}
+		r.gitDir = gitDir
+	}
+	r.commonDir = r.gitDir
+	if b, err := os.ReadFile(filepath.Join(r.gitDir, "commondir")); err == nil {
+		r.commonDir = strings.TrimSpace(string(b))
+		if !filepath.IsAbs(r.commonDir) {
+			r.commonDir = filepath.Join(r.gitDir, r.commonDir)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(r.commonDir, "reftable")); err == nil {
+		return nil, fmt.Errorf("the native backend doesn't read reftable refs, use the exec backend")
+	}
+
+	var err error
+	if r.packedRefs, err = readPackedRefs(filepath.Join(r.commonDir, "packed-refs")); err != nil {
+		return nil, err
+	}
+	if r.shallow, err = readShallow(filepath.Join(r.commonDir, "shallow")); err != nil {
+		return nil, err
+	}
+	if r.objects, err = openObjectStore(filepath.Join(r.commonDir, "objects")); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *repository) close() {
+	r.objects.close()
+}
+
+// readPackedRefs reads the refs of the packed-refs file by name. Peeled tags, the lines starting with ^, are skipped:
+// tags are peeled by reading their objects.
+func readPackedRefs(file string) (map[string]string, error) {
+	refs := make(map[string]string)
+	b, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return refs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read packed refs err=%w", err)
+	}
+	for _, line := range splitAndFilter(string(b), "\n") {
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "^") {
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) == 2 {
+			refs[fields[1]] = fields[0]
+		}
+	}
+	return refs, nil
+}
+
+func readShallow(file string) (map[objectID]bool, error) {
+	shallow := make(map[objectID]bool)
+	b, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return shallow, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read shallow commits err=%w", err)
+	}
+	for _, line := range splitAndFilter(string(b), "\n") {
+		id, err := parseObjectID(strings.TrimSpace(line))
+		if err != nil {
+			return nil, err
+		}
+		shallow[id] = true
+	}
+	return shallow, nil
+}
+
+// resolve returns the object a ref points to, following symbolic refs such as HEAD.
+func (r *repository) resolve(name string) (objectID, error) {
+	ref := name
+	for depth := 0; depth < 5; depth++ {
+		value, err := r.readRef(ref)
+		if err != nil {
+			return objectID{}, err
+		}
+		if !strings.HasPrefix(value, "ref:") {
+			return parseObjectID(value)
+		}
+		ref = strings.TrimSpace(strings.TrimPrefix(value, "ref:"))
+	}
+	return objectID{}, fmt.Errorf("too many levels of symbolic refs ref=%s", name)
+}
+
+// head returns the commit of HEAD, or of the Ref of g when set. The Ref is looked up in-process when it is a full hash
+// or a ref name, which is looked up like git does, ie. main is refs/heads/main unless a tag has the name. Any other
+// revision, ie. HEAD~2 or an abbreviated hash, is resolved by git.
+func (r *repository) head(ctx context.Context, g Git) (objectID, error) {
+	rev := g.ref()
+	id, err := parseObjectID(rev)
+	if err != nil {
+		id, err = r.resolveName(rev)
+	}
+	if errors.Is(err, errUnknownRevision) && rev != "HEAD" {
+		var out []byte
+		if out, err = g.output(ctx, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}"); err == nil {
+			id, err = parseObjectID(strings.TrimSpace(string(out)))
+		}
+	}
+	if err != nil {
+		return objectID{}, err
+	}
+	commit, ok, err := r.peel(id)
+	if err != nil {
+		return objectID{}, err
+	}
+	if !ok {
+		return objectID{}, fmt.Errorf("rev=%s doesn't point to a commit", rev)
+	}
+	return commit, nil
+}
+
+// refRules are the refs a short name is looked up in, in order, like git does.
+var refRules = []string{"refs/%s", "refs/tags/%s", "refs/heads/%s", "refs/remotes/%s", "refs/remotes/%s/HEAD"}
+
+// resolveName resolves HEAD, a full ref name or a short one looked up in the refRules.
+func (r *repository) resolveName(name string) (objectID, error) {
+	if strings.Contains(name, "..") || strings.ContainsAny(name, "~^:?*[\\ ") {
+		// not a ref name but a revision of git, ie. HEAD~2
+		return objectID{}, fmt.Errorf("%w ref=%s", errUnknownRevision, name)
+	}
+	if name == "HEAD" || strings.HasPrefix(name, "refs/") {
+		return r.resolve(name)
+	}
+	for _, rule := range refRules {
+		id, err := r.resolve(fmt.Sprintf(rule, name))
+		if !errors.Is(err, errUnknownRevision) {
+			return id, err
+		}
+	}
+	return objectID{}, fmt.Errorf("%w ref=%s", errUnknownRevision, name)
+}
+
+// readRef reads the value of a loose or packed ref. HEAD is read from the git directory of the worktree.
+func (r *repository) readRef(name string) (string, error) {
+	dir := r.commonDir
+	if name == "HEAD" {
+		dir = r.gitDir
+	}
+	b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err == nil {
+		return strings.TrimSpace(string(b)), nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("could not read ref=%s err=%w", name, err)
+	}
+	if value, ok := r.packedRefs[name]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("%w ref=%s", errUnknownRevision, name)
+}
+
+// tags returns the names of the tags, loose or packed, without their refs/tags/ prefix.
+func (r *repository) tags() ([]string, error) {
+	names := make(map[string]bool)
+	for ref := range r.packedRefs {
+		if strings.HasPrefix(ref, "refs/tags/") {
+			names[strings.TrimPrefix(ref, "refs/tags/")] = true
+		}
+	}
+	dir := filepath.Join(r.commonDir, "refs", "tags")
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		names[filepath.ToSlash(name)] = true
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list tags err=%w", err)
+	}
+
+	tags := make([]string, 0, len(names))
+	for name := range names {
+		tags = append(tags, name)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// peel returns the commit a tag points to, through any annotated tag objects. The second return value is false when
+// it points to another kind of object.
+func (r *repository) peel(id objectID) (objectID, bool, error) {
+	for depth := 0; depth < 10; depth++ {
+		o, err := r.objects.read(id)
+		if err != nil {
+			return objectID{}, false, err
+		}
+		switch o.typ {
+		case objectCommit:
+			return id, true, nil
+		case objectTag:
+			target := strings.TrimPrefix(strings.SplitN(string(o.data), "\n", 2)[0], "object ")
+			if id, err = parseObjectID(target); err != nil {
+				return objectID{}, false, fmt.Errorf("invalid tag object err=%w", err)
+			}
+		default:
+			return objectID{}, false, nil
+		}
+	}
+	return objectID{}, false, fmt.Errorf("too many levels of tag objects")
+}
+
+// listTags lists the tags matching the glob pattern sorted by name, like git tag --list does.
+func (r *repository) listTags(pattern string) ([]string, error) {
+	tags, err := r.tags()
+	if err != nil {
+		return nil, err
+	}
+	match, err := globMatcher(pattern)
+	if err != nil {
+		return nil, err
+	}
+	matching := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if match(t) {
+			matching = append(matching, t)
+		}
+	}
+	return matching, nil
+}
+
+// globMatcher matches names against a glob pattern the way git tag --list does: a * matches any characters, slashes
+// included, a ? any single character and brackets a class of characters.
+func globMatcher(pattern string) (func(string) bool, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta(pattern[i:]))
+				i = len(pattern)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag pattern=%s err=%w", pattern, err)
+	}
+	return re.MatchString, nil
+}
+
+// latestTag is GetLatestTag read from the repository.
+func (r *repository) latestTag(ctx context.Context, g Git) (string, error) {
+	tags, err := r.listTags(g.TagPrefix + "*")
+	if err != nil || len(tags) == 0 {
+		return "", err
+	}
+	head, err := r.head(ctx, g)
+	if err != nil {
+		return "", err
+	}
+
+	// only the tags which could be the latest are looked up in the history of HEAD
+	candidates := make(map[objectID][]string)
+	for _, t := range tags {
+		if _, ok := g.Scope.parseTag(t); !ok {
+			continue
+		}
+		id, err := r.resolve("refs/tags/" + t)
+		if err != nil {
+			return "", err
+		}
+		commit, ok, err := r.peel(id)
+		if err != nil {
+			return "", fmt.Errorf("could not peel tag=%s err=%w", t, err)
+		}
+		if ok {
+			candidates[commit] = append(candidates[commit], t)
+		}
+	}
+	reachable, err := newRevWalk(r).reachable(ctx, head, candidates)
+	if err != nil {
+		return "", err
+	}
+	merged := make([]string, 0, len(tags))
+	for commit := range reachable {
+		merged = append(merged, candidates[commit]...)
+	}
+	return g.Scope.latestOf(merged), nil
+}
+
+// commitsSince lists the commits of HEAD since the tag, every commit of HEAD when the tag is empty, in the order git
+// log lists them. HEAD is the Ref of g when set.
+func (r *repository) commitsSince(ctx context.Context, g Git, tag string) ([]*commitNode, error) {
+	head, err := r.head(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+	var exclude []objectID
+	if tag != "" {
+		id, err := r.resolve("refs/tags/" + tag)
+		if err != nil {
+			return nil, err
+		}
+		commit, ok, err := r.peel(id)
+		if err != nil || !ok {
+			return nil, fmt.Errorf("tag=%s doesn't point to a commit err=%v", tag, err)
+		}
+		exclude = append(exclude, commit)
+	}
+	return newRevWalk(r).walk(ctx, head, exclude)
+}
+
+// describe is Describe read from the repository.
+func (r *repository) describe(ctx context.Context, g Git) (Description, error) {
+	tag, err := r.latestTag(ctx, g)
+	if err != nil {
+		return Description{}, err
+	}
+	commits, err := r.commitsSince(ctx, g, tag)
+	if err != nil {
+		return Description{}, fmt.Errorf("could not count commits since tag=%s err=%w", tag, err)
+	}
+	head, err := r.head(ctx, g)
+	if err != nil {
+		return Description{}, err
+	}
+	hash, err := r.objects.abbrev(head)
+	if err != nil {
+		return Description{}, fmt.Errorf("could not abbreviate %s err=%w", g.ref(), err)
+	}
+	return Description{Tag: tag, Distance: len(commits), Hash: hash}, nil
+}
+
+// forEachCommitSinceLatestTag is ForEachCommitSinceLatestTag read from the repository. The walk holds the raw commits,
+// each is decoded only when fn is called with it.
+func (r *repository) forEachCommitSinceLatestTag(ctx context.Context, g Git, fn func(Commit) error) error {
+	tag, err := r.latestTag(ctx, g)
+	if err != nil {
+		return err
+	}
+	nodes, err := r.commitsSince(ctx, g, tag)
+	if err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		c, err := n.commit()
+		if err != nil {
+			return err
+		}
+		if err = fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func (s *NativeTestSuite) TestNativeBackend() {
+	ctx := context.Background()
+	s.createHistory()
+	s.assertSameAnswers(s.Git)
+	s.assertSameAnswers(s.scoped(Scope{ExcludePrereleases: true}))
+	s.assertSameAnswers(s.scoped(Scope{TagPrefix: "lib/"}))
+	s.assertSameRefs()
+
+	// gc packs the objects, deltas included, and the refs
+	s.git("gc", "--quiet", "--aggressive")
+	s.NoFileExists(filepath.Join(s.Git.WorkDirectory, ".git", "refs", "tags", "v1.0.0"))
+	s.assertSameAnswers(s.Git)
+	s.assertSameAnswers(s.scoped(Scope{ExcludePrereleases: true}))
+	s.assertSameAnswers(s.scoped(Scope{TagPrefix: "lib/"}))
+	s.assertSameRefs()
+
+	// a new loose tag and commits on top of the packs
+	_, _ = s.Git.CreateCommit(ctx, "feat: after gc", "", true)
+	s.git("tag", "-a", "v1.3.0", "-m", "v1.3.0")
+	_, _ = s.Git.CreateCommit(ctx, "fix: after the tag", "", true)
+	s.assertSameAnswers(s.Git)
+}
+
+func (s *NativeTestSuite) TestNativeBackendWithoutTags() {
+	ctx := context.Background()
+	native := s.Git.WithBackend(BackendNative)
+	tag, err := native.GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("", tag)
+	_, err = native.GetCommitsSinceLatestTag(ctx)
+	s.Error(err, "HEAD has no commit yet")
+
+	_, _ = s.Git.CreateCommit(ctx, "feat: first", "", true)
+	_, _ = s.Git.CreateCommit(ctx, "feat: second", "", true)
+	s.assertSameAnswers(s.Git)
+}
+
+func (s *NativeTestSuite) TestNativeBackendShallowClone() {
+	s.createHistory()
+	clone := s.T().TempDir()
+	s.git("clone", "--quiet", "--depth", "3", "file://"+s.Git.WorkDirectory, clone)
+	s.assertSameAnswers(New(clone))
+
+	s.git("-C", clone, "fetch", "--quiet", "--tags", "--depth", "6")
+	s.assertSameAnswers(New(clone))
+}
+
+func (s *NativeTestSuite) TestNativeBackendWorktree() {
+	s.createHistory()
+	worktree := filepath.Join(s.T().TempDir(), "worktree")
+	s.git("worktree", "add", "--quiet", worktree, "feature")
+	s.assertSameAnswers(New(worktree))
+	s.assertSameAnswers(New(filepath.Join(s.Git.WorkDirectory, "lib")))
+}
+
+func (s *NativeTestSuite) TestObjectStore() {
+	s.createHistory()
+	s.git("gc", "--quiet", "--aggressive")
+	r, err := openRepository(s.Git.WorkDirectory)
+	if err != nil {
+		s.FailNow("could not open repository", err)
+	}
+	defer r.close()
+
+	// every object, deltas included, reads like git cat-file prints it
+	cmd := exec.Command("git", "-C", s.Git.WorkDirectory, "cat-file", "--batch-all-objects", "--batch")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		s.FailNow("could not run git", err)
+	}
+	if err = cmd.Start(); err != nil {
+		s.FailNow("could not run git", err)
+	}
+	objects := bufio.NewReader(out)
+	var count int
+	for {
+		header, err := objects.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+		fields := strings.Fields(header)
+		size, _ := strconv.Atoi(fields[2])
+		data := make([]byte, size+1)
+		if _, err = io.ReadFull(objects, data); err != nil {
+			s.FailNow("could not read git cat-file", err)
+		}
+		id, _ := parseObjectID(fields[0])
+		o, err := r.objects.read(id)
+		s.NoError(err)
+		s.Equal(objectTypes[fields[1]], o.typ, fields[0])
+		s.Equal(string(data[:size]), string(o.data), fields[0])
+		count++
+	}
+	s.NoError(cmd.Wait())
+	s.Greater(count, 30)
+
+	// abbreviations are as long as the ones git prints
+	for _, line := range splitAndFilter(s.git("log", "--all", "--format=%H %h%n%T %t"), "\n") {
+		fields := strings.Fields(line)
+		id, _ := parseObjectID(fields[0])
+		abbrev, err := r.objects.abbrev(id)
+		s.NoError(err)
+		s.Equal(fields[1], abbrev)
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("the quick brown fox")
+	// sizes 19 and 15, copy 11 bytes at offset 4, insert " cat"
+	delta := []byte{19, 15, 0x80 | 0x01 | 0x10, 4, 11, 4, ' ', 'c', 'a', 't'}
+	out, err := applyDelta(base, delta)
+	assert.NoError(t, err)
+	assert.Equal(t, "quick brown cat", string(out))
+
+	_, err = applyDelta(base[:5], delta)
+	assert.Error(t, err)
+	_, err = applyDelta(base, []byte{19, 15, 0x80 | 0x01 | 0x10, 15, 11})
+	assert.Error(t, err, "copy out of the base")
+}
+
+func TestSplitMessage(t *testing.T) {
+	subject, body := splitMessage("feat: a subject\nover two lines  \n\n\nthe body\n\nBREAKING CHANGE: all of it\n")
+	assert.Equal(t, "feat: a subject over two lines", subject)
+	assert.Equal(t, "the body\n\nBREAKING CHANGE: all of it", body)
+
+	subject, body = splitMessage("fix: no body\n")
+	assert.Equal(t, "fix: no body", subject)
+	assert.Equal(t, "", body)
+}
+
+func TestGlobMatcher(t *testing.T) {
+	match, err := globMatcher("lib/v1.[0-2].*-rc.?")
+	assert.NoError(t, err)
+	assert.True(t, match("lib/v1.2.0-rc.1"))
+	assert.True(t, match("lib/v1.0.sub/dir-rc.1"))
+	assert.False(t, match("lib/v1.3.0-rc.1"))
+	assert.False(t, match("lib/v1.2.0-rc.10"))
+}
+
+// createHistory creates tags of every kind on a history with merges and commits of the same second. A lib directory
+// is tagged with its own prefix.
+func (s *NativeTestSuite) createHistory() {
+	ctx := context.Background()
+	commitFile := func(name string, subject string, line int) {
+		file := filepath.Join(s.Git.WorkDirectory, name)
+		// a growing file gives git blobs to deltify
+		content := strings.Repeat("a line of the file which is long enough to be worth a delta\n", 40)
+		content += strings.Repeat(strconv.Itoa(line)+"\n", line)
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			s.FailNow("could not write file", err)
+		}
+		if err := s.Git.Add(ctx, file); err != nil {
+			s.FailNow("could not stage file", err)
+		}
+		if _, err := s.Git.CreateCommit(ctx, subject, "", false); err != nil {
+			s.FailNow("could not create commit", err)
+		}
+	}
+
+	commitFile("file.txt", "feat: first", 1)
+	s.git("tag", "v1.0.0")
+	s.git("tag", "deploy-prod")
+	commitFile("file.txt", "fix: a ~~ separator", 2)
+	_, _ = s.Git.CreateCommit(ctx, "feat: a subject\nover two lines", "the body\n\nBREAKING CHANGE: ~~ all of it", true)
+	s.git("tag", "-a", "v1.1.0-rc.1", "-m", "release candidate")
+	s.git("checkout", "--quiet", "-b", "feature")
+	commitFile("feature.txt", "feat: on the feature branch", 3)
+	if err := os.MkdirAll(filepath.Join(s.Git.WorkDirectory, "lib"), 0755); err != nil {
+		s.FailNow("could not create lib", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.Git.WorkDirectory, "lib", "lib.go"), []byte("package lib"), 0644); err != nil {
+		s.FailNow("could not write lib", err)
+	}
+	_ = s.Git.Add(ctx, "lib")
+	_, _ = s.Git.CreateCommit(ctx, "feat(lib): add the lib", "", false)
+	s.git("tag", "-a", "lib/v0.1.0", "-m", "lib/v0.1.0")
+	s.git("checkout", "--quiet", "-")
+	commitFile("file.txt", "fix: on main", 4)
+	s.git("merge", "--quiet", "--no-ff", "-m", "Merge branch 'feature'", "feature")
+	for i := 5; i < 10; i++ {
+		commitFile("file.txt", "chore: commit "+strconv.Itoa(i), i)
+	}
+	s.git("tag", "v1.2.0")
+	commitFile("file.txt", "feat: after the release", 10)
+	s.git("checkout", "--quiet", "-b", "unmerged")
+	_, _ = s.Git.CreateCommit(ctx, "feat!: never merged", "", true)
+	s.git("tag", "v2.0.0")
+	s.git("checkout", "--quiet", "-")
+	_, _ = s.Git.CreateCommit(ctx, "fix: last", "", true)
+}
+
+// assertSameAnswers asserts the native backend answers the tag and commit queries of g like the exec backend.
+// scoped returns a copy of the suite's Git restricted to the scope.
+func (s *NativeTestSuite) scoped(scope Scope) Git {
+	g := s.Git
+	g.Scope = scope
+	return g
+}
+
+func (s *NativeTestSuite) assertSameAnswers(g Git) {
+	ctx := context.Background()
+	native := g.WithBackend(BackendNative)
+
+	tags, err := g.ListTags(ctx, g.TagPrefix+"*")
+	s.NoError(err)
+	nativeTags, err := native.ListTags(ctx, g.TagPrefix+"*")
+	s.NoError(err)
+	s.Equal(tags, nativeTags)
+
+	tag, err := g.GetLatestTag(ctx)
+	s.NoError(err)
+	nativeTag, err := native.GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal(tag, nativeTag)
+
+	description, err := g.Describe(ctx)
+	s.NoError(err)
+	nativeDescription, err := native.Describe(ctx)
+	s.NoError(err)
+	s.Equal(description, nativeDescription)
+
+	preRelease, err := g.GetLatestPreReleaseTag(ctx)
+	s.NoError(err)
+	nativePreRelease, err := native.GetLatestPreReleaseTag(ctx)
+	s.NoError(err)
+	s.Equal(preRelease, nativePreRelease)
+
+	commits, err := g.GetCommitsSinceLatestTag(ctx)
+	s.NoError(err)
+	nativeCommits, err := native.GetCommitsSinceLatestTag(ctx)
+	s.NoError(err)
+	s.Equal(commits, nativeCommits)
+}
+
+// assertSameRefs asserts the native backend answers like the exec backend for revisions other than HEAD: branches,
+// tags, hashes and revisions only git resolves.
+func (s *NativeTestSuite) assertSameRefs() {
+	hash := strings.TrimSpace(s.git("rev-parse", "feature"))
+	for _, ref := range []string{"feature", "refs/heads/unmerged", "v1.1.0-rc.1", "lib/v0.1.0", hash, hash[:9], "HEAD~3",
+		"feature^"} {
+		s.assertSameAnswers(s.Git.WithRef(ref))
+	}
+}
+
+// git runs a git command in the repository and returns its output.
+func (s *NativeTestSuite) git(args ...string) string {
+	out, err := exec.Command("git", append([]string{"-C", s.Git.WorkDirectory}, args...)...).CombinedOutput()
+	if err != nil {
+		s.FailNow("git failed", "args=%v err=%v out=%s", args, err, out)
+	}
+	return string(out)
+}
+
+func TestNativeTestSuite(t *testing.T) {
+	suite.Run(t, new(NativeTestSuite))
+}
+
+type NativeTestSuite struct {
+	GitTestSuite
+}
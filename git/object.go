@@ -0,0 +1,554 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// objectID is the SHA-1 name of a git object.
+type objectID [20]byte
+
+func parseObjectID(s string) (objectID, error) {
+	var id objectID
+	if len(s) != hex.EncodedLen(len(id)) {
+		// SHA-256 repositories have 64 characters long names
+		return id, fmt.Errorf("invalid object name=%s, only SHA-1 repositories are supported", s)
+	}
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return id, fmt.Errorf("invalid object name=%s err=%w", s, err)
+	}
+	return id, nil
+}
+
+func (id objectID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// Object types as packfiles number them
+const (
+	objectCommit   = 1
+	objectTree     = 2
+	objectBlob     = 3
+	objectTag      = 4
+	objectOfsDelta = 6
+	objectRefDelta = 7
+)
+
+var objectTypes = map[string]int{"commit": objectCommit, "tree": objectTree, "blob": objectBlob, "tag": objectTag}
+
+// maxCachedBases is the number of delta bases kept in memory by an objectStore.
+const maxCachedBases = 256
+
+// objectStore reads loose and packed objects from the objects directories of a repository.
+type objectStore struct {
+	// dirs are the objects directories, the one of the repository first followed by its alternates.
+	dirs  []string
+	packs []*pack
+	// bases caches the delta bases read from the packs, deltas of the same base are usually read together.
+	bases map[packedObject]object
+}
+
+// object is the type and content of a git object.
+type object struct {
+	typ  int
+	data []byte
+}
+
+// packedObject locates an object in a pack.
+type packedObject struct {
+	pack   *pack
+	offset int64
+}
+
+func openObjectStore(dir string) (*objectStore, error) {
+	s := &objectStore{bases: make(map[packedObject]object)}
+	if err := s.addDir(dir, 0); err != nil {
+		s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// addDir adds the objects directory dir and its alternates, which may have alternates themselves.
+func (s *objectStore) addDir(dir string, depth int) error {
+	s.dirs = append(s.dirs, dir)
+	idxFiles, err := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+	if err != nil {
+		return err
+	}
+	for _, f := range idxFiles {
+		p, err := openPack(f)
+		if err != nil {
+			return err
+		}
+		s.packs = append(s.packs, p)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "info", "alternates"))
+	if errors.Is(err, fs.ErrNotExist) || depth >= 5 {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read alternates err=%w", err)
+	}
+	for _, alternate := range splitAndFilter(string(b), "\n") {
+		if strings.HasPrefix(alternate, "#") {
+			continue
+		}
+		if !filepath.IsAbs(alternate) {
+			alternate = filepath.Join(dir, alternate)
+		}
+		if err = s.addDir(alternate, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *objectStore) close() {
+	for _, p := range s.packs {
+		p.close()
+	}
+}
+
+// read reads the object named id, packed or loose.
+func (s *objectStore) read(id objectID) (object, error) {
+	for _, p := range s.packs {
+		i, found, err := p.search(id)
+		if err != nil {
+			return object{}, err
+		}
+		if found {
+			offset, err := p.offset(i)
+			if err != nil {
+				return object{}, err
+			}
+			return s.readPacked(packedObject{pack: p, offset: offset})
+		}
+	}
+	for _, dir := range s.dirs {
+		o, err := readLoose(filepath.Join(dir, id.String()[:2], id.String()[2:]))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return object{}, fmt.Errorf("could not read object=%s err=%w", id, err)
+		}
+		return o, nil
+	}
+	return object{}, fmt.Errorf("object=%s not found", id)
+}
+
+// readLoose reads the zlib compressed loose object file, made of a "<type> <size>" header, a NUL and the content.
+func readLoose(file string) (object, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return object{}, err
+	}
+	defer f.Close()
+	zr, err := zlib.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return object{}, err
+	}
+	defer zr.Close()
+	r := bufio.NewReader(zr)
+	header, err := r.ReadString(0)
+	if err != nil {
+		return object{}, fmt.Errorf("invalid loose object header err=%w", err)
+	}
+	fields := strings.Fields(strings.TrimSuffix(header, "\x00"))
+	if len(fields) != 2 {
+		return object{}, fmt.Errorf("invalid loose object header=%q", header)
+	}
+	typ, ok := objectTypes[fields[0]]
+	size, err := strconv.Atoi(fields[1])
+	if !ok || err != nil {
+		return object{}, fmt.Errorf("invalid loose object header=%q", header)
+	}
+	data := make([]byte, size)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return object{}, fmt.Errorf("truncated loose object err=%w", err)
+	}
+	return object{typ: typ, data: data}, nil
+}
+
+// readPacked reads the object at the offset of a pack and resolves it against its base when it's a delta.
+func (s *objectStore) readPacked(at packedObject) (object, error) {
+	r := bufio.NewReader(io.NewSectionReader(at.pack.data, at.offset, at.pack.size-at.offset))
+	typ, size, err := readPackedHeader(r)
+	if err != nil {
+		return object{}, fmt.Errorf("invalid object header in pack=%s offset=%d err=%w", at.pack.name, at.offset, err)
+	}
+
+	var base object
+	switch typ {
+	case objectCommit, objectTree, objectBlob, objectTag:
+	case objectOfsDelta:
+		distance, err := readBaseDistance(r)
+		if err != nil || distance <= 0 || distance > at.offset {
+			return object{}, fmt.Errorf("invalid delta base in pack=%s offset=%d", at.pack.name, at.offset)
+		}
+		base, err = s.readBase(packedObject{pack: at.pack, offset: at.offset - distance})
+		if err != nil {
+			return object{}, err
+		}
+	case objectRefDelta:
+		var id objectID
+		if _, err = io.ReadFull(r, id[:]); err != nil {
+			return object{}, fmt.Errorf("invalid delta base in pack=%s offset=%d err=%w", at.pack.name, at.offset, err)
+		}
+		base, err = s.read(id)
+		if err != nil {
+			return object{}, err
+		}
+	default:
+		return object{}, fmt.Errorf("unknown object type=%d in pack=%s offset=%d", typ, at.pack.name, at.offset)
+	}
+
+	data, err := inflate(r, size)
+	if err != nil {
+		return object{}, fmt.Errorf("could not inflate object in pack=%s offset=%d err=%w", at.pack.name, at.offset, err)
+	}
+	if typ != objectOfsDelta && typ != objectRefDelta {
+		return object{typ: typ, data: data}, nil
+	}
+	data, err = applyDelta(base.data, data)
+	if err != nil {
+		return object{}, fmt.Errorf("invalid delta in pack=%s offset=%d err=%w", at.pack.name, at.offset, err)
+	}
+	return object{typ: base.typ, data: data}, nil
+}
+
+// readBase reads a delta base through the cache of bases.
+func (s *objectStore) readBase(at packedObject) (object, error) {
+	if o, ok := s.bases[at]; ok {
+		return o, nil
+	}
+	o, err := s.readPacked(at)
+	if err != nil {
+		return object{}, err
+	}
+	if len(s.bases) >= maxCachedBases {
+		s.bases = make(map[packedObject]object)
+	}
+	s.bases[at] = o
+	return o, nil
+}
+
+// readPackedHeader reads the type and inflated size of a packed object. The size is a little endian base 128 number
+// of which the first byte holds 4 bits next to the type.
+func readPackedHeader(r io.ByteReader) (int, int, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	typ := int(c>>4) & 7
+	size := int(c & 0x0f)
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = r.ReadByte(); err != nil {
+			return 0, 0, err
+		}
+		size |= int(c&0x7f) << shift
+	}
+	return typ, size, nil
+}
+
+// readBaseDistance reads how far before an offset delta its base is. Each continuation adds one, so that every
+// distance has a single encoding.
+func readBaseDistance(r io.ByteReader) (int64, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	distance := int64(c & 0x7f)
+	for c&0x80 != 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+		distance = ((distance + 1) << 7) | int64(c&0x7f)
+	}
+	return distance, nil
+}
+
+func inflate(r io.Reader, size int) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	data := make([]byte, size)
+	if _, err = io.ReadFull(zr, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// applyDelta rebuilds an object from its base and a delta. The delta starts with the sizes of the base and of the
+// object, followed by instructions copying a range of the base or inserting the bytes following them.
+func applyDelta(base []byte, delta []byte) ([]byte, error) {
+	r := bytes.NewReader(delta)
+	baseSize, err := binary.ReadUvarint(r)
+	if err != nil || baseSize != uint64(len(base)) {
+		return nil, fmt.Errorf("base size=%d doesn't match the delta", len(base))
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object size err=%w", err)
+	}
+
+	out := make([]byte, 0, size)
+	for {
+		op, err := r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		switch {
+		case op&0x80 != 0:
+			var offset, n uint64
+			for i := uint(0); i < 4; i++ {
+				if op&(1<<i) != 0 {
+					b, err := r.ReadByte()
+					if err != nil {
+						return nil, fmt.Errorf("truncated copy instruction")
+					}
+					offset |= uint64(b) << (8 * i)
+				}
+			}
+			for i := uint(0); i < 3; i++ {
+				if op&(0x10<<i) != 0 {
+					b, err := r.ReadByte()
+					if err != nil {
+						return nil, fmt.Errorf("truncated copy instruction")
+					}
+					n |= uint64(b) << (8 * i)
+				}
+			}
+			if n == 0 {
+				n = 0x10000
+			}
+			if offset+n > uint64(len(base)) {
+				return nil, fmt.Errorf("copy instruction out of the base offset=%d size=%d", offset, n)
+			}
+			out = append(out, base[offset:offset+n]...)
+		case op != 0:
+			insert := make([]byte, op)
+			if _, err = io.ReadFull(r, insert); err != nil {
+				return nil, fmt.Errorf("truncated insert instruction")
+			}
+			out = append(out, insert...)
+		default:
+			return nil, fmt.Errorf("reserved instruction")
+		}
+	}
+	if uint64(len(out)) != size {
+		return nil, fmt.Errorf("object size=%d doesn't match the delta size=%d", len(out), size)
+	}
+	return out, nil
+}
+
+// Layout of a version 2 pack index: a header, a fan-out table of 256 cumulative counts by first byte of the object
+// names, the sorted names, their CRC32, their 4 bytes offsets in the pack and the 8 bytes offsets too large for 31
+// bits.
+const (
+	idxHeaderSize = 8
+	idxFanoutSize = 256 * 4
+	idxNames      = idxHeaderSize + idxFanoutSize
+)
+
+var idxMagic = []byte{0xff, 't', 'O', 'c', 0, 0, 0, 2}
+
+// pack is a packfile and its index. The index is read on demand rather than loaded, it can be large.
+type pack struct {
+	name   string
+	idx    *os.File
+	data   *os.File
+	size   int64
+	fanout [256]uint32
+	count  int64
+}
+
+func openPack(idxFile string) (*pack, error) {
+	p := &pack{name: filepath.Base(idxFile)}
+	var err error
+	if p.idx, err = os.Open(idxFile); err != nil {
+		return nil, fmt.Errorf("could not open pack index err=%w", err)
+	}
+	if p.data, err = os.Open(strings.TrimSuffix(idxFile, ".idx") + ".pack"); err != nil {
+		p.close()
+		return nil, fmt.Errorf("could not open pack err=%w", err)
+	}
+	fi, err := p.data.Stat()
+	if err != nil {
+		p.close()
+		return nil, fmt.Errorf("could not open pack err=%w", err)
+	}
+	p.size = fi.Size()
+
+	header := make([]byte, idxNames)
+	if _, err = p.idx.ReadAt(header, 0); err != nil || !bytes.Equal(header[:idxHeaderSize], idxMagic) {
+		p.close()
+		return nil, fmt.Errorf("unsupported pack index=%s, only version 2 is supported", p.name)
+	}
+	for i := range p.fanout {
+		p.fanout[i] = binary.BigEndian.Uint32(header[idxHeaderSize+4*i:])
+	}
+	p.count = int64(p.fanout[255])
+	return p, nil
+}
+
+func (p *pack) close() {
+	if p.idx != nil {
+		_ = p.idx.Close()
+	}
+	if p.data != nil {
+		_ = p.data.Close()
+	}
+}
+
+// name returns the object name at the position i of the index.
+func (p *pack) nameAt(i int64) (objectID, error) {
+	var id objectID
+	_, err := p.idx.ReadAt(id[:], idxNames+i*int64(len(id)))
+	if err != nil {
+		return id, fmt.Errorf("could not read pack index=%s err=%w", p.name, err)
+	}
+	return id, nil
+}
+
+// search returns the position of the object named id in the index, or the position it would be inserted at when the
+// pack doesn't have it.
+func (p *pack) search(id objectID) (int64, bool, error) {
+	lo := int64(0)
+	if id[0] > 0 {
+		lo = int64(p.fanout[id[0]-1])
+	}
+	hi := int64(p.fanout[id[0]])
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		name, err := p.nameAt(mid)
+		if err != nil {
+			return 0, false, err
+		}
+		switch c := bytes.Compare(name[:], id[:]); {
+		case c == 0:
+			return mid, true, nil
+		case c < 0:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return lo, false, nil
+}
+
+// offset returns the offset in the pack of the object at the position i of the index.
+func (p *pack) offset(i int64) (int64, error) {
+	offsets := idxNames + p.count*(int64(len(objectID{}))+4)
+	b := make([]byte, 8)
+	if _, err := p.idx.ReadAt(b[:4], offsets+4*i); err != nil {
+		return 0, fmt.Errorf("could not read pack index=%s err=%w", p.name, err)
+	}
+	offset := binary.BigEndian.Uint32(b)
+	if offset&0x80000000 == 0 {
+		return int64(offset), nil
+	}
+	large := offsets + 4*p.count + 8*int64(offset&0x7fffffff)
+	if _, err := p.idx.ReadAt(b, large); err != nil {
+		return 0, fmt.Errorf("could not read pack index=%s err=%w", p.name, err)
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+// minAbbrevLength is the shortest abbreviation of an object name, git's fallback for core.abbrev.
+const minAbbrevLength = 7
+
+// abbrev abbreviates the object name like git does by default: long enough to be unlikely to collide as the repository
+// grows, and long enough to be unique among the objects of the repository.
+func (s *objectStore) abbrev(id objectID) (string, error) {
+	var count int64
+	for _, p := range s.packs {
+		count += p.count
+	}
+	// 2^bits objects expect a collision at 2^(bits/2), with 4 bits per hexadecimal character
+	bits := 1
+	for c := count >> 1; c > 0; c >>= 1 {
+		bits++
+	}
+	length := (bits + 1) / 2
+	if length < minAbbrevLength {
+		length = minAbbrevLength
+	}
+
+	extend := func(other objectID) {
+		if other == id {
+			return
+		}
+		if common := commonHexPrefix(id, other); common >= length {
+			length = common + 1
+		}
+	}
+	for _, p := range s.packs {
+		i, found, err := p.search(id)
+		if err != nil {
+			return "", err
+		}
+		next := i
+		if found {
+			next++
+		}
+		for _, j := range []int64{i - 1, next} {
+			if j < 0 || j >= p.count {
+				continue
+			}
+			other, err := p.nameAt(j)
+			if err != nil {
+				return "", err
+			}
+			extend(other)
+		}
+	}
+	hexID := id.String()
+	for _, dir := range s.dirs {
+		entries, err := os.ReadDir(filepath.Join(dir, hexID[:2]))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("could not list loose objects err=%w", err)
+		}
+		for _, e := range entries {
+			if other, err := parseObjectID(hexID[:2] + e.Name()); err == nil {
+				extend(other)
+			}
+		}
+	}
+	if length > len(hexID) {
+		length = len(hexID)
+	}
+	return hexID[:length], nil
+}
+
+// commonHexPrefix returns the number of leading hexadecimal characters two object names have in common.
+func commonHexPrefix(a objectID, b objectID) int {
+	for i := range a {
+		if a[i] == b[i] {
+			continue
+		}
+		if a[i]>>4 == b[i]>>4 {
+			return 2*i + 1
+		}
+		return 2 * i
+	}
+	return 2 * len(a)
+}
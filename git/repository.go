@@ -0,0 +1,54 @@
+package git
+
+import (
+	"context"
+)
+
+// Repository answers the tag and commit queries versioning a repository takes. Git runs them against a repository on
+// disk, Memory against a commit graph described in code, ie. to unit test release rules without running git.
+type Repository interface {
+	// GetLatestTag returns the tag of the highest semantic version in the scope reachable from HEAD, empty when there
+	// is none.
+	GetLatestTag(ctx context.Context) (string, error)
+	// GetLatestPreReleaseTag describes HEAD relative to the latest tag, ie. v1.0.2-4-123aefd
+	GetLatestPreReleaseTag(ctx context.Context) (string, error)
+	// Describe locates HEAD relative to the latest tag.
+	Describe(ctx context.Context) (Description, error)
+	// ListTags lists the tags matching the glob pattern, ie. v1.2.0-rc.*
+	ListTags(ctx context.Context, pattern string) ([]string, error)
+	// GetCommitsSinceLatestTag returns the commits reachable from HEAD since the latest tag, newest first.
+	GetCommitsSinceLatestTag(ctx context.Context) ([]Commit, error)
+	// ForEachCommitSinceLatestTag calls fn with each commit GetCommitsSinceLatestTag returns without holding them all.
+	ForEachCommitSinceLatestTag(ctx context.Context, fn func(Commit) error) error
+	// GetHeadCommit returns the commit HEAD points at.
+	GetHeadCommit(ctx context.Context) (Commit, error)
+	// IsDirty is true when the working tree has uncommitted changes.
+	IsDirty(ctx context.Context) (bool, error)
+	// DiffHash returns a short hash of the uncommitted changes.
+	DiffHash(ctx context.Context) (string, error)
+	// CreateAnnotatedTag tags HEAD with an annotated tag of the given message.
+	CreateAnnotatedTag(ctx context.Context, tag string, message string) error
+	// WithScope returns a copy of the Repository which only looks up the tags and commits of the scope.
+	WithScope(scope Scope) Repository
+}
+
+// Scope restricts the tags and commits a Repository looks up.
+type Scope struct {
+	// TagPrefix restricts the tags looked up to the ones starting with the prefix. An empty prefix matches every tag.
+	TagPrefix string
+	// Paths restricts the commits looked up to the ones touching the pathspecs. No paths matches every commit.
+	Paths []string
+	// ExcludePrereleases ignores the pre-release tags (ie. v1.2.0-rc.1) when looking up the latest tag.
+	ExcludePrereleases bool
+}
+
+// WithScope returns a copy of the Git which only looks up the tags and commits of the scope.
+func (g Git) WithScope(scope Scope) Repository {
+	g.Scope = scope
+	return g
+}
+
+var (
+	_ Repository = Git{}
+	_ Repository = Memory{}
+)
@@ -0,0 +1,54 @@
+package git
+
+import (
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDirty is returned when an operation requires a clean working tree but it has uncommitted or untracked changes.
+var ErrDirty = errors.New("working tree has uncommitted changes")
+
+// IsDirty reports whether the working tree has uncommitted or untracked changes. Only the changes touching the Paths
+// count when set. A Ref other than HEAD is never dirty, the working tree is not what it versions.
+func (g Git) IsDirty(ctx context.Context) (bool, error) {
+	if g.ref() != "HEAD" {
+		return false, nil
+	}
+	out, err := g.output(ctx, "status", g.withPaths("--porcelain", "--untracked-files=normal")...)
+	if err != nil {
+		return false, fmt.Errorf("could not read the status of the working tree err=%w", err)
+	}
+	return strings.TrimSpace(string(out)) != "", nil
+}
+
+// DiffHash returns a short hash of the uncommitted changes, tracked or untracked, of the working tree. Two trees with
+// the same changes on top of the same commit have the same hash. Only the changes touching the Paths count when set.
+func (g Git) DiffHash(ctx context.Context) (string, error) {
+	diff, err := g.output(ctx, "diff", g.withPaths("HEAD", "--binary")...)
+	if err != nil {
+		return "", fmt.Errorf("could not diff the working tree err=%w", err)
+	}
+	untracked, err := g.output(ctx, "ls-files", g.withPaths("--others", "--exclude-standard")...)
+	if err != nil {
+		return "", fmt.Errorf("could not list untracked files err=%w", err)
+	}
+
+	h := sha1.New()
+	h.Write(diff)
+	files := splitAndFilter(string(untracked), "\n")
+	if len(files) > 0 {
+		// untracked files are not part of the diff, their content is hashed by git instead
+		cmd := g.exec(ctx, "hash-object", "--stdin-paths")
+		cmd.Stdin = strings.NewReader(strings.Join(files, "\n"))
+		blobs, err := run(ctx, cmd)
+		if err != nil {
+			return "", fmt.Errorf("could not hash untracked files err=%w", err)
+		}
+		h.Write(untracked)
+		h.Write(blobs)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:shortHashLength], nil
+}
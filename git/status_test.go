@@ -0,0 +1,87 @@
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func (s StatusTestSuite) TestIsDirty() {
+	ctx := context.Background()
+	file := filepath.Join(s.Git.WorkDirectory, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		s.FailNow("could not write file", err)
+	}
+	dirty, err := s.Git.IsDirty(ctx)
+	s.NoError(err)
+	s.True(dirty, "untracked files make the tree dirty")
+
+	if err = s.Git.Add(ctx, "main.go"); err != nil {
+		s.FailNow("could not stage file", err)
+	}
+	if _, err = s.Git.CreateCommit(ctx, "feat: main", "", false); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	dirty, err = s.Git.IsDirty(ctx)
+	s.NoError(err)
+	s.False(dirty)
+
+	if err = os.WriteFile(file, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		s.FailNow("could not write file", err)
+	}
+	dirty, err = s.Git.IsDirty(ctx)
+	s.NoError(err)
+	s.True(dirty)
+
+	dirty, err = s.Git.WithScope(Scope{Paths: []string{"docs"}}).IsDirty(ctx)
+	s.NoError(err)
+	s.False(dirty, "changes outside of the paths don't count")
+}
+
+func (s StatusTestSuite) TestDiffHash() {
+	ctx := context.Background()
+	file := filepath.Join(s.Git.WorkDirectory, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		s.FailNow("could not write file", err)
+	}
+	if err := s.Git.Add(ctx, "main.go"); err != nil {
+		s.FailNow("could not stage file", err)
+	}
+	if _, err := s.Git.CreateCommit(ctx, "feat: main", "", false); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+
+	if err := os.WriteFile(file, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		s.FailNow("could not write file", err)
+	}
+	modified, err := s.Git.DiffHash(ctx)
+	s.NoError(err)
+	s.Len(modified, 7)
+	again, err := s.Git.DiffHash(ctx)
+	s.NoError(err)
+	s.Equal(modified, again)
+
+	if err = os.WriteFile(filepath.Join(s.Git.WorkDirectory, "notes.txt"), []byte("todo"), 0644); err != nil {
+		s.FailNow("could not write file", err)
+	}
+	untracked, err := s.Git.DiffHash(ctx)
+	s.NoError(err)
+	s.NotEqual(modified, untracked)
+
+	if err = os.WriteFile(filepath.Join(s.Git.WorkDirectory, "notes.txt"), []byte("done"), 0644); err != nil {
+		s.FailNow("could not write file", err)
+	}
+	changed, err := s.Git.DiffHash(ctx)
+	s.NoError(err)
+	s.NotEqual(untracked, changed)
+}
+
+func TestStatusTestSuite(t *testing.T) {
+	suite.Run(t, new(StatusTestSuite))
+}
+
+type StatusTestSuite struct {
+	GitTestSuite
+}
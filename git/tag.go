@@ -1,84 +1,173 @@
 package git
 
 import (
+	"context"
 	"fmt"
+	"github.com/Masterminds/semver"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 // CreateTag creates a git lightweight tag by default. Setting annotated to true will create
-// an annotated tag.
-func (g Git) CreateTag(tag string, annotated bool) error {
-	args := []string{tag}
+// an annotated tag with the tag name as its message. The tag points at HEAD, or at the commit of the Ref when set.
+func (g Git) CreateTag(ctx context.Context, tag string, annotated bool) error {
 	if annotated {
-		args = append(args, "-a")
+		return g.CreateAnnotatedTag(ctx, tag, tag)
 	}
-	err := g.exec("tag", args...).Run()
+	_, err := g.output(ctx, "tag", tag, g.ref()+"^{commit}")
 	if err != nil {
-		return fmt.Errorf("could not create git tag=%s err=%v", tag, err)
+		return fmt.Errorf("could not create git tag=%s err=%w", tag, err)
 	}
 	return nil
 }
 
-// GetLatestPreReleaseTag fetches the latest abbreviated tag from the git repository.
-// Note: This function removes the "g" from the human-readable tag (ie. 1.0.2-4-g123aefd)
-func (g Git) GetLatestPreReleaseTag() (string, error) {
-	// check if there are any tags
-	out, err := g.exec("tag", "--list").Output()
+// CreateAnnotatedTag creates an annotated git tag with the given message, see CreateTag for the commit it points at.
+// The message is passed directly to git so no editor is opened.
+func (g Git) CreateAnnotatedTag(ctx context.Context, tag string, message string) error {
+	_, err := g.output(ctx, "tag", "-a", tag, "-m", message, g.ref()+"^{commit}")
 	if err != nil {
-		return "", err
-	}
-	if strings.Trim(string(out), "\n") == "" {
-		return "", nil
+		return fmt.Errorf("could not create git tag=%s err=%w", tag, err)
 	}
+	return nil
+}
 
-	out, err = g.exec("describe", "--tags").Output()
+// semverTag matches a strict semantic version with an optional v in front of it, ie. v1.2.3-rc.1+build.5
+var semverTag = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(-(0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(\.(0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*)?` +
+	`(\+[0-9a-zA-Z-]+(\.[0-9a-zA-Z-]+)*)?$`)
+
+// Description locates HEAD, or the Ref when set, relative to the latest tag.
+type Description struct {
+	// Tag is the latest tag, empty when there is none.
+	Tag string
+	// Distance is the number of commits since the Tag, or since the first commit without a tag.
+	Distance int
+	// Hash is the abbreviated hash of HEAD, or of the commit of the Ref.
+	Hash string
+}
+
+// Describe locates HEAD relative to the latest tag, like git describe does for the nearest tag.
+func (g Git) Describe(ctx context.Context) (Description, error) {
+	if g.Backend == BackendNative {
+		var d Description
+		err := g.native(func(r *repository) (err error) {
+			d, err = r.describe(ctx, g)
+			return err
+		})
+		return d, err
+	}
+	tag, err := g.GetLatestTag(ctx)
 	if err != nil {
-		return "", err
+		return Description{}, err
+	}
+	revRange := g.ref()
+	if tag != "" {
+		revRange = fmt.Sprintf("refs/tags/%s..%s", tag, g.ref())
 	}
-	var b strings.Builder
-	_ , err = b.Write(out)
+	out, err := g.output(ctx, "rev-list", "--count", revRange, "--")
 	if err != nil {
-		return "", err
+		return Description{}, fmt.Errorf("could not count commits since tag=%s err=%w", tag, err)
+	}
+	distance, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return Description{}, fmt.Errorf("could not count commits since tag=%s err=%w", tag, err)
 	}
-	sanitizedOutput := strings.TrimSuffix(b.String(), "\n")
 	//The length of the abbreviation scales as the repository grows, using the approximate number of objects in
 	//the repository and a bit of math around the birthday paradox, and defaults to a minimum of 7.
-	//The "g" prefix stands for "git" and is used to allow describing the version of a software
-	//depending on the SCM the software is managed with. This is useful in an environment where
-	//people may use different SCMs.
-	hashRegex := regexp.MustCompile(`-g\w+`)
-	res := hashRegex.FindString(sanitizedOutput)
-	if res != "" {
-		sanitizedOutput = strings.ReplaceAll(sanitizedOutput, "-g", "-")
+	out, err = g.output(ctx, "rev-parse", "--short", g.ref()+"^{commit}")
+	if err != nil {
+		return Description{}, fmt.Errorf("could not abbreviate %s err=%w", g.ref(), err)
 	}
-
-	return sanitizedOutput, nil
+	return Description{Tag: tag, Distance: distance, Hash: strings.TrimSpace(string(out))}, nil
 }
 
-// GetLatestTag fetches the latest git tag in the tree.
-func (g Git) GetLatestTag() (string, error) {
-	if ok := g.hasTagHistory(); !ok{
-		return "", nil
+// GetLatestPreReleaseTag describes HEAD relative to the latest tag, ie. v1.0.2-4-123aefd for the 4th commit after
+// v1.0.2. The latest tag alone is returned when HEAD is tagged.
+func (g Git) GetLatestPreReleaseTag(ctx context.Context) (string, error) {
+	d, err := g.Describe(ctx)
+	if err != nil || d.Tag == "" {
+		return "", err
 	}
+	if d.Distance == 0 {
+		return d.Tag, nil
+	}
+	return fmt.Sprintf("%s-%d-%s", d.Tag, d.Distance, d.Hash), nil
+}
 
-	out, err := g.exec("describe", "--tags", "--abbrev=0").Output()
-	if err != nil {
+// GetLatestTag returns the tag of the highest semantic version reachable from HEAD, or from the Ref when set. Only the
+// tags made of the TagPrefix followed by a valid semantic version count, tags such as deploy-prod are skipped.
+// Pre-release tags are skipped too when ExcludePrereleases is set. Of several tags of the same version, ie. v1.2.0 and
+// v1.2.0+build.1 on the same commit, the first by name wins. An empty tag is returned when there is none.
+func (g Git) GetLatestTag(ctx context.Context) (string, error) {
+	if g.Backend == BackendNative {
+		var tag string
+		err := g.native(func(r *repository) (err error) {
+			tag, err = r.latestTag(ctx, g)
+			return err
+		})
+		return tag, err
+	}
+	tags, err := g.ListTags(ctx, g.TagPrefix + "*")
+	if err != nil || len(tags) == 0 {
+		// without any tag there is nothing to look up, HEAD may not even exist yet
 		return "", err
 	}
-	var b strings.Builder
-	_ , err = b.Write(out)
+	out, err := g.output(ctx, "tag", "--merged", g.ref(), "--list", g.TagPrefix+"*")
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("could not list tags reachable from %s err=%w", g.ref(), err)
+	}
+
+	return g.Scope.latestOf(splitAndFilter(string(out), "\n")), nil
+}
+
+// latestOf returns the tag of the highest semantic version of the tags in the scope, see GetLatestTag.
+func (s Scope) latestOf(tags []string) string {
+	var latest string
+	var latestVersion *semver.Version
+	for _, t := range tags {
+		v, ok := s.parseTag(t)
+		if !ok {
+			continue
+		}
+		if latestVersion == nil || v.GreaterThan(latestVersion) || (v.Equal(latestVersion) && t < latest) {
+			latest, latestVersion = t, v
+		}
+	}
+	return latest
+}
+
+// parseTag parses the semantic version of a tag made of the TagPrefix followed by a semantic version. The second
+// return value is false for any other tag and for pre-release tags when ExcludePrereleases is set.
+func (s Scope) parseTag(tag string) (*semver.Version, bool) {
+	if !strings.HasPrefix(tag, s.TagPrefix) {
+		return nil, false
 	}
-	sanitizedOutput := strings.TrimSuffix(b.String(), "\n")
-	return sanitizedOutput, nil
+	// a trailing v of the prefix may as well be the v of the version
+	version := strings.TrimPrefix(tag, strings.TrimSuffix(s.TagPrefix, "v"))
+	if !semverTag.MatchString(version) {
+		return nil, false
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil || (s.ExcludePrereleases && v.Prerelease() != "") {
+		return nil, false
+	}
+	return v, true
 }
 
-func (g Git) hasTagHistory() bool {
-	out, err := g.exec("tag", "--list").Output()
+// ListTags lists the tags matching the glob pattern, ie. v1.2.0-rc.*
+func (g Git) ListTags(ctx context.Context, pattern string) ([]string, error) {
+	if g.Backend == BackendNative {
+		var tags []string
+		err := g.native(func(r *repository) (err error) {
+			tags, err = r.listTags(pattern)
+			return err
+		})
+		return tags, err
+	}
+	out, err := g.output(ctx, "tag", "--list", pattern)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	return strings.Trim(string(out), "\n") != ""
-}
\ No newline at end of file
+	return splitAndFilter(string(out), "\n"), nil
+}
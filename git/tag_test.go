@@ -1,53 +1,237 @@
 package git
 
 import (
+	"context"
 	"fmt"
 	"github.com/stretchr/testify/suite"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
-func (s TagTestSuite) GetLatestPreReleaseTag() {
-	if _, err := s.Git.CreateCommit("first commit", "", true); err != nil {
+func (s TagTestSuite) TestGetLatestPreReleaseTag() {
+	ctx := context.Background()
+	if _, err := s.Git.CreateCommit(ctx, "first commit", "", true); err != nil {
 		s.Error(err, "could not create commit")
 	}
-	if _, err := s.Git.CreateCommit("second commit", "", true); err != nil {
+	if _, err := s.Git.CreateCommit(ctx, "second commit", "", true); err != nil {
 		s.Error(err, "could not create commit")
 	}
 
-	if err := s.Git.CreateTag("v0.1.0", false); err != nil {
+	if err := s.Git.CreateTag(ctx, "v0.1.0", false); err != nil {
 		s.Error(err, "could not create tag")
 	}
-	if _, err := s.Git.CreateCommit("third commit", "", true); err != nil {
+	if _, err := s.Git.CreateCommit(ctx, "third commit", "", true); err != nil {
 		s.Error(err, "could not create commit")
 	}
 
-	if err := s.Git.CreateTag("v0.2.0", false); err != nil {
+	if err := s.Git.CreateTag(ctx, "v0.2.0", false); err != nil {
 		s.Error(err, "could not create tag")
 	}
 
-	tag, err := s.Git.GetLatestPreReleaseTag()
+	tag, err := s.Git.GetLatestPreReleaseTag(ctx)
 	if err != nil {
 		s.Error(err, "could not get latest tag")
 	}
 	s.Equal("v0.2.0", tag)
 
-	_, err = s.Git.CreateCommit("fourth commit", "", true)
+	_, err = s.Git.CreateCommit(ctx, "fourth commit", "", true)
 	if err != nil {
 		s.Error(err, "could not create commit")
 	}
 
-	c, err := s.Git.CreateCommit("fifth commit", "", true)
+	c, err := s.Git.CreateCommit(ctx, "fifth commit", "", true)
 	if err != nil {
 		s.Error(err, "could not create commit")
 	}
 
-	tag, err = s.Git.GetLatestPreReleaseTag()
+	tag, err = s.Git.GetLatestPreReleaseTag(ctx)
 	if err != nil {
 		s.Error(err, "could not get latest tag")
 	}
 	s.Equal(fmt.Sprintf("v0.2.0-2-%s", c.Hash[0:7]), tag)
 }
 
+func (s TagTestSuite) TestCreateAnnotatedTag() {
+	ctx := context.Background()
+	if _, err := s.Git.CreateCommit(ctx, "first commit", "", true); err != nil {
+		s.Error(err, "could not create commit")
+	}
+	if err := s.Git.CreateAnnotatedTag(ctx, "v1.0.0", "Release 1.0.0\n\n- first commit"); err != nil {
+		s.FailNow("could not create annotated tag", err)
+	}
+
+	out, err := s.Git.exec(ctx, "cat-file", "-t", "v1.0.0").Output()
+	if err != nil {
+		s.FailNow("could not read tag object", err)
+	}
+	s.Equal("tag\n", string(out))
+
+	out, err = s.Git.exec(ctx, "tag", "-l", "--format=%(contents)", "v1.0.0").Output()
+	if err != nil {
+		s.FailNow("could not read tag message", err)
+	}
+	s.Equal("Release 1.0.0\n\n- first commit\n\n", string(out))
+
+	if err = s.Git.CreateTag(ctx, "v1.0.1", true); err != nil {
+		s.FailNow("could not create annotated tag without a message", err)
+	}
+	out, err = s.Git.exec(ctx, "tag", "-l", "--format=%(contents)", "v1.0.1").Output()
+	if err != nil {
+		s.FailNow("could not read tag message", err)
+	}
+	s.Equal("v1.0.1\n\n", string(out))
+}
+
+func (s TagTestSuite) TestGetLatestTagWithoutPrereleases() {
+	ctx := context.Background()
+	if _, err := s.Git.CreateCommit(ctx, "first commit", "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	if err := s.Git.CreateTag(ctx, "v1.2.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if _, err := s.Git.CreateCommit(ctx, "second commit", "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	if err := s.Git.CreateTag(ctx, "v1.3.0-rc.1", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+
+	tag, err := s.Git.GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("v1.3.0-rc.1", tag)
+
+	tag, err = s.Git.WithScope(Scope{ExcludePrereleases: true}).GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("v1.2.0", tag)
+
+	tags, err := s.Git.ListTags(ctx, "v1.3.0-rc.*")
+	s.NoError(err)
+	s.Equal([]string{"v1.3.0-rc.1"}, tags)
+}
+
+func (s TagTestSuite) TestGetLatestTag() {
+	ctx := context.Background()
+	if _, err := s.Git.CreateCommit(ctx, "first commit", "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	for _, tag := range []string{"v1.2.0", "v1.10.0-rc.1", "v1.10.0"} {
+		if err := s.Git.CreateTag(ctx, tag, false); err != nil {
+			s.FailNow("could not create tag", err)
+		}
+	}
+	if _, err := s.Git.CreateCommit(ctx, "second commit", "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	for _, tag := range []string{"deploy-prod", "v2", "v1.9.0", "v02.0.0"} {
+		if err := s.Git.CreateTag(ctx, tag, false); err != nil {
+			s.FailNow("could not create tag", err)
+		}
+	}
+	if err := s.Git.exec(ctx, "checkout", "--quiet", "-b", "other").Run(); err != nil {
+		s.FailNow("could not create branch", err)
+	}
+	if _, err := s.Git.CreateCommit(ctx, "unreachable commit", "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	if err := s.Git.CreateTag(ctx, "v3.0.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if err := s.Git.exec(ctx, "checkout", "--quiet", "-").Run(); err != nil {
+		s.FailNow("could not checkout branch", err)
+	}
+
+	tag, err := s.Git.GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("v1.10.0", tag)
+
+	tag, err = s.Git.WithScope(Scope{TagPrefix: "v"}).GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("v1.10.0", tag)
+
+	tag, err = s.Git.WithScope(Scope{TagPrefix: "release-"}).GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("", tag)
+
+	commits, err := s.Git.GetCommitsSinceLatestTag(ctx)
+	s.NoError(err)
+	s.Len(commits, 1)
+	s.Equal("second commit", commits[0].Subject)
+}
+
+func (s TagTestSuite) TestRef() {
+	ctx := context.Background()
+	if _, err := s.Git.CreateCommit(ctx, "first commit", "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	if err := s.Git.CreateAnnotatedTag(ctx, "v1.0.0", "v1.0.0"); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if err := s.Git.exec(ctx, "checkout", "--quiet", "-b", "feature").Run(); err != nil {
+		s.FailNow("could not create branch", err)
+	}
+	if _, err := s.Git.CreateCommit(ctx, "feature commit", "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	feature, err := s.Git.CreateCommit(ctx, "second feature commit", "", true)
+	if err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	if err = s.Git.exec(ctx, "checkout", "--quiet", "-").Run(); err != nil {
+		s.FailNow("could not checkout branch", err)
+	}
+	if _, err = s.Git.CreateCommit(ctx, "main commit", "", true); err != nil {
+		s.FailNow("could not create commit", err)
+	}
+	if err = s.Git.CreateTag(ctx, "v1.1.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+
+	// the feature branch is looked up without checking it out, a file of the same name doesn't make it ambiguous
+	if err = os.WriteFile(filepath.Join(s.Git.WorkDirectory, "feature"), []byte("todo"), 0644); err != nil {
+		s.FailNow("could not write file", err)
+	}
+	g := s.Git.WithRef("feature")
+	tag, err := g.GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("v1.0.0", tag)
+	d, err := g.Describe(ctx)
+	s.NoError(err)
+	s.Equal(Description{Tag: "v1.0.0", Distance: 2, Hash: feature.ShortHash()}, d)
+	commits, err := g.GetCommitsSinceLatestTag(ctx)
+	s.NoError(err)
+	s.Len(commits, 2)
+	head, err := g.GetHeadCommit(ctx)
+	s.NoError(err)
+	s.Equal(feature.Hash, head.Hash)
+
+	// the tags point at the ref, annotated tags are peeled to their commit
+	if err = g.CreateAnnotatedTag(ctx, "v1.1.0-rc.1", "release candidate"); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	tag, err = s.Git.WithRef(feature.ShortHash()).GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("v1.1.0-rc.1", tag)
+	d, err = s.Git.WithRef("v1.0.0").Describe(ctx)
+	s.NoError(err)
+	s.Equal(0, d.Distance)
+
+	// the working tree is not what the ref versions
+	if err = os.WriteFile(filepath.Join(s.Git.WorkDirectory, "notes.txt"), []byte("todo"), 0644); err != nil {
+		s.FailNow("could not write file", err)
+	}
+	dirty, err := g.IsDirty(ctx)
+	s.NoError(err)
+	s.False(dirty)
+	dirty, err = s.Git.WithRef("HEAD").IsDirty(ctx)
+	s.NoError(err)
+	s.True(dirty)
+
+	_, err = s.Git.WithRef("unknown").GetLatestTag(ctx)
+	s.Error(err)
+}
+
 func TestTagTestSuite(t *testing.T) {
 	suite.Run(t, new(TagTestSuite))
 }
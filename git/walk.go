@@ -0,0 +1,331 @@
+package git
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commitNode is a commit of a revWalk.
+type commitNode struct {
+	id      objectID
+	parents []objectID
+	// date is the committer timestamp, the walk lists the newest commits first.
+	date int64
+	data []byte
+}
+
+// Flags of the commits of a revWalk
+const (
+	// seen commits have been queued.
+	seen = 1 << iota
+	// uninteresting commits are reachable from an excluded commit.
+	uninteresting
+)
+
+// slop is how many more uninteresting commits a walk looks at once only uninteresting commits are left, in case clock
+// skew hides an interesting one behind them. It is the value of git.
+const slop = 5
+
+// revWalk walks the history of a repository like git rev-list does without any ordering option: newest committer date
+// first, commits of the same date in the order they were reached.
+type revWalk struct {
+	repo    *repository
+	commits map[objectID]*commitNode
+	flags   map[objectID]int
+	queue   commitQueue
+}
+
+func newRevWalk(r *repository) *revWalk {
+	return &revWalk{repo: r, commits: make(map[objectID]*commitNode), flags: make(map[objectID]int)}
+}
+
+// load reads and parses the headers of the commit id. The parents of the commits of a shallow clone are not loaded,
+// git considers them to have none.
+func (w *revWalk) load(id objectID) (*commitNode, error) {
+	if c, ok := w.commits[id]; ok {
+		return c, nil
+	}
+	o, err := w.repo.objects.read(id)
+	if err != nil {
+		return nil, err
+	}
+	if o.typ != objectCommit {
+		return nil, fmt.Errorf("object=%s is not a commit", id)
+	}
+	c := &commitNode{id: id, data: o.data}
+	for _, line := range strings.Split(string(headers(o.data)), "\n") {
+		switch {
+		case strings.HasPrefix(line, "parent ") && !w.repo.shallow[id]:
+			parent, err := parseObjectID(strings.TrimPrefix(line, "parent "))
+			if err != nil {
+				return nil, fmt.Errorf("invalid commit=%s err=%w", id, err)
+			}
+			c.parents = append(c.parents, parent)
+		case strings.HasPrefix(line, "committer "):
+			_, _, when, err := parseIdent(strings.TrimPrefix(line, "committer "))
+			if err != nil {
+				return nil, fmt.Errorf("invalid commit=%s err=%w", id, err)
+			}
+			c.date = when.Unix()
+		}
+	}
+	w.commits[id] = c
+	return c, nil
+}
+
+// push queues the commit id unless it has been seen already.
+func (w *revWalk) push(id objectID) error {
+	if w.flags[id]&seen != 0 {
+		return nil
+	}
+	c, err := w.load(id)
+	if err != nil {
+		return err
+	}
+	w.flags[id] |= seen
+	heap.Push(&w.queue, queued{commit: c, order: w.queue.pushed})
+	w.queue.pushed++
+	return nil
+}
+
+// markUninteresting marks the commit id and the ancestors of it loaded so far uninteresting.
+func (w *revWalk) markUninteresting(id objectID) {
+	stack := []objectID{id}
+	for len(stack) > 0 {
+		id, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		if w.flags[id]&uninteresting != 0 {
+			continue
+		}
+		w.flags[id] |= uninteresting
+		if c, ok := w.commits[id]; ok {
+			stack = append(stack, c.parents...)
+		}
+	}
+}
+
+// walk lists the commits reachable from include but not from any of the exclude commits, like git log exclude..include
+// does.
+func (w *revWalk) walk(ctx context.Context, include objectID, exclude []objectID) ([]*commitNode, error) {
+	for _, id := range exclude {
+		w.markUninteresting(id)
+		if err := w.push(id); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.push(include); err != nil {
+		return nil, err
+	}
+
+	var listed []*commitNode
+	date := int64(math.MaxInt64)
+	remaining := slop
+	for w.queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("could not walk the history err=%w", err)
+		}
+		c := heap.Pop(&w.queue).(queued).commit
+		if w.flags[c.id]&uninteresting != 0 {
+			for _, p := range c.parents {
+				w.markUninteresting(p)
+				if err := w.push(p); err != nil {
+					return nil, err
+				}
+			}
+			if remaining = w.stillInteresting(date, remaining); remaining > 0 {
+				continue
+			}
+			break
+		}
+		for _, p := range c.parents {
+			if err := w.push(p); err != nil {
+				return nil, err
+			}
+		}
+		date = c.date
+		listed = append(listed, c)
+	}
+
+	// commits listed before an uninteresting descendant was reached are dropped
+	commits := make([]*commitNode, 0, len(listed))
+	for _, c := range listed {
+		if w.flags[c.id]&uninteresting == 0 {
+			commits = append(commits, c)
+		}
+	}
+	return commits, nil
+}
+
+// stillInteresting returns how many more uninteresting commits the walk looks at, 0 when it is done. The date is the
+// one of the last listed commit.
+func (w *revWalk) stillInteresting(date int64, remaining int) int {
+	if w.queue.Len() == 0 {
+		return 0
+	}
+	if date <= w.queue.items[0].commit.date {
+		return slop
+	}
+	for _, q := range w.queue.items {
+		if w.flags[q.commit.id]&uninteresting == 0 {
+			return slop
+		}
+	}
+	return remaining - 1
+}
+
+// reachable returns the targets reachable from the commit from.
+func (w *revWalk) reachable(ctx context.Context, from objectID, targets map[objectID][]string) (map[objectID]bool, error) {
+	found := make(map[objectID]bool)
+	if len(targets) == 0 {
+		return found, nil
+	}
+	stack := []objectID{from}
+	w.flags[from] |= seen
+	for len(stack) > 0 && len(found) < len(targets) {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("could not walk the history err=%w", err)
+		}
+		var id objectID
+		id, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		if _, ok := targets[id]; ok {
+			found[id] = true
+		}
+		c, err := w.load(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range c.parents {
+			if w.flags[p]&seen == 0 {
+				w.flags[p] |= seen
+				stack = append(stack, p)
+			}
+		}
+	}
+	return found, nil
+}
+
+// queued is a commit of a commitQueue. The order it was queued in breaks the ties between commits of the same date.
+type queued struct {
+	commit *commitNode
+	order  int
+}
+
+// commitQueue is a heap of commits, the newest first.
+type commitQueue struct {
+	items  []queued
+	pushed int
+}
+
+func (q commitQueue) Len() int { return len(q.items) }
+
+func (q commitQueue) Less(i, j int) bool {
+	if q.items[i].commit.date != q.items[j].commit.date {
+		return q.items[i].commit.date > q.items[j].commit.date
+	}
+	return q.items[i].order < q.items[j].order
+}
+
+func (q commitQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+func (q *commitQueue) Push(x interface{}) { q.items = append(q.items, x.(queued)) }
+
+func (q *commitQueue) Pop() interface{} {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return last
+}
+
+// strictISO8601 is the layout of the %cI dates of git log, which unlike time.RFC3339 prints UTC as +00:00.
+const strictISO8601 = "2006-01-02T15:04:05-07:00"
+
+// headers returns the headers of a commit object, the lines before the first empty line.
+func headers(data []byte) []byte {
+	if i := bytes.Index(data, []byte("\n\n")); i >= 0 {
+		return data[:i]
+	}
+	return data
+}
+
+// commit converts the commit object to a Commit, the way git log prints the fields of the commitLogFormat.
+func (c *commitNode) commit() (Commit, error) {
+	commit := Commit{Hash: c.id.String()}
+	for _, line := range strings.Split(string(headers(c.data)), "\n") {
+		var err error
+		switch {
+		case strings.HasPrefix(line, "author "):
+			commit.Author.Name, commit.Author.Email, _, err = parseIdent(strings.TrimPrefix(line, "author "))
+		case strings.HasPrefix(line, "committer "):
+			var when time.Time
+			if _, _, when, err = parseIdent(strings.TrimPrefix(line, "committer ")); err == nil {
+				// parsed back like the exec backend parses the strict ISO 8601 date of git log
+				commit.Date, err = time.Parse(time.RFC3339, when.Format(strictISO8601))
+			}
+		}
+		if err != nil {
+			return Commit{}, fmt.Errorf("invalid commit=%s err=%w", c.id, err)
+		}
+	}
+
+	var message string
+	if i := bytes.Index(c.data, []byte("\n\n")); i >= 0 {
+		message = string(c.data[i+2:])
+	}
+	commit.Subject, commit.Body = splitMessage(message)
+	return commit, nil
+}
+
+// splitMessage splits a commit message into its subject and body like git log does. The subject is the first paragraph
+// with its lines joined by spaces, the body the rest of the message.
+func splitMessage(message string) (string, string) {
+	lines := strings.SplitAfter(message, "\n")
+	i := 0
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" && lines[i] != "" {
+		i++
+	}
+	var subject []string
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		subject = append(subject, strings.TrimRight(lines[i], " \t\n\r\v\f"))
+	}
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" && lines[i] != "" {
+		i++
+	}
+	body := strings.Join(lines[i:], "")
+	return strings.Join(subject, " "), strings.Trim(body, "\n")
+}
+
+// parseIdent parses the name, email and date of an author or committer header, ie.
+//
+//	megatron <megatron@email.com> 1622534400 +0200
+func parseIdent(ident string) (string, string, time.Time, error) {
+	open := strings.IndexByte(ident, '<')
+	end := strings.LastIndexByte(ident, '>')
+	if open < 0 || end < open {
+		return "", "", time.Time{}, fmt.Errorf("invalid ident=%q", ident)
+	}
+	name := strings.TrimSpace(ident[:open])
+	email := ident[open+1 : end]
+
+	fields := strings.Fields(ident[end+1:])
+	if len(fields) != 2 || len(fields[1]) != 5 {
+		return "", "", time.Time{}, fmt.Errorf("invalid date in ident=%q", ident)
+	}
+	seconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("invalid date in ident=%q", ident)
+	}
+	hours, errHours := strconv.Atoi(fields[1][1:3])
+	minutes, errMinutes := strconv.Atoi(fields[1][3:])
+	if errHours != nil || errMinutes != nil {
+		return "", "", time.Time{}, fmt.Errorf("invalid time zone in ident=%q", ident)
+	}
+	offset := hours*3600 + minutes*60
+	if fields[1][0] == '-' {
+		offset = -offset
+	}
+	return name, email, time.Unix(seconds, 0).In(time.FixedZone("", offset)), nil
+}
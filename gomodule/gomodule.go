@@ -0,0 +1,104 @@
+package gomodule
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const goModFile = "go.mod"
+
+// Module is a Go module of a repository.
+type Module struct {
+	// Dir is the slash separated directory of the module relative to the repository root, "." for the root module.
+	Dir string
+	// Nested are the directories of the modules nested inside Dir. Their files don't belong to this module.
+	Nested []string
+}
+
+// TagPrefix is the prefix Go expects in front of the version of the module's tags, ie. "sub/dir/" for sub/dir/v1.2.3.
+func (m Module) TagPrefix() string {
+	if m.Dir == "." {
+		return ""
+	}
+	return m.Dir + "/"
+}
+
+// Pathspecs are the git pathspecs matching the files of the module, excluding the nested modules.
+func (m Module) Pathspecs() []string {
+	specs := []string{m.Dir}
+	for _, n := range m.Nested {
+		specs = append(specs, fmt.Sprintf(":(exclude)%s", n))
+	}
+	return specs
+}
+
+// Discover walks the repository in root and returns every directory with a go.mod file, sorted by directory.
+// Hidden directories, vendor and testdata are skipped like the go command does.
+func Discover(root string) ([]Module, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+				name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != goModFile {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not discover go modules in %s err=%w", root, err)
+	}
+	sort.Strings(dirs)
+
+	modules := make([]Module, 0, len(dirs))
+	for _, dir := range dirs {
+		m := Module{Dir: dir}
+		for _, other := range dirs {
+			if other != dir && isParent(dir, other) {
+				m.Nested = append(m.Nested, other)
+			}
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+// Find returns the module of the repository in root with the directory dir.
+func Find(root string, dir string) (Module, error) {
+	modules, err := Discover(root)
+	if err != nil {
+		return Module{}, err
+	}
+	dir = path.Clean(filepath.ToSlash(dir))
+	for _, m := range modules {
+		if m.Dir == dir {
+			return m, nil
+		}
+	}
+	return Module{}, fmt.Errorf("no go module in directory %s", dir)
+}
+
+// isParent reports whether the slash separated directory child is inside parent.
+func isParent(parent string, child string) bool {
+	if parent == "." {
+		return true
+	}
+	return strings.HasPrefix(child, parent+"/")
+}
@@ -0,0 +1,46 @@
+package gomodule
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{".", "api", "api/client", "tools/gen", "vendor/x", ".hidden", "api/testdata"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("could not create directory err=%v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "go.mod"), []byte("module example.com/x"), 0644); err != nil {
+			t.Fatalf("could not create go.mod err=%v", err)
+		}
+	}
+
+	modules, err := Discover(root)
+	assert.NoError(t, err)
+	assert.Equal(t, []Module{
+		{Dir: ".", Nested: []string{"api", "api/client", "tools/gen"}},
+		{Dir: "api", Nested: []string{"api/client"}},
+		{Dir: "api/client"},
+		{Dir: "tools/gen"},
+	}, modules)
+
+	m, err := Find(root, "api/")
+	assert.NoError(t, err)
+	assert.Equal(t, "api", m.Dir)
+
+	_, err = Find(root, "tools")
+	assert.Error(t, err)
+}
+
+func TestModule(t *testing.T) {
+	root := Module{Dir: ".", Nested: []string{"api"}}
+	assert.Equal(t, "", root.TagPrefix())
+	assert.Equal(t, []string{".", ":(exclude)api"}, root.Pathspecs())
+
+	api := Module{Dir: "api"}
+	assert.Equal(t, "api/", api.TagPrefix())
+	assert.Equal(t, []string{"api"}, api.Pathspecs())
+}
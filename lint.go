@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"github.com/hooliganlin/versioning/semversioner/config"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/hooliganlin/versioning/semversioner/release"
+	"io"
+	"os"
+)
+
+// LintCommand validates commit messages against the conventional commit specification.
+type LintCommand struct {
+	File  string `long:"file" description:"Lint the commit message in the file, ie. the argument of a commit-msg hook"`
+	Range string `long:"range" description:"Lint every commit of the revision range (ie. origin/main..HEAD), defaults to the commits since the latest tag"`
+}
+
+// Execute prints every violation, ie.
+//	c100381: unknown-type: type "feta" is not one of build, chore, ci, docs, feat, fix, perf, refactor, revert, style, test
+// and fails when there is any.
+func (c *LintCommand) Execute(_ []string) error {
+	ctx, cancel := opts.context()
+	defer cancel()
+	cfg, err := config.Load(opts.WorkDir)
+	if err != nil {
+		return err
+	}
+	var violations int
+	if c.File != "" {
+		b, err := os.ReadFile(c.File)
+		if err != nil {
+			return fmt.Errorf("could not read commit message file=%s err=%w", c.File, err)
+		}
+		violations = printViolations(os.Stdout, c.File, conventional.Lint(string(b), lintTypes(cfg)))
+	} else {
+		lint := func(commit git.Commit) error {
+			message := commit.Subject + "\n\n" + commit.Body
+			violations += printViolations(os.Stdout, commit.ShortHash(), conventional.Lint(message, lintTypes(cfg)))
+			return nil
+		}
+		if c.Range != "" {
+			// a range names its commits, no version is determined so the branch policies don't apply. A range may be
+			// the whole history, its commits are linted as git lists them
+			err = git.New(opts.WorkDir).WithBackend(opts.GitBackend).ForEachCommit(ctx, c.Range, lint)
+		} else {
+			var g git.Git
+			var rules conventional.Rules
+			if g, rules, _, err = release.Load(ctx, opts.releaseOptions()); err != nil {
+				return err
+			}
+			var commits []git.Commit
+			commits, err = rules.Scope(g).GetCommitsSinceLatestTag(ctx)
+			for _, commit := range commits {
+				_ = lint(commit)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("could not fetch commits err=%w", err)
+		}
+	}
+
+	if violations > 0 {
+		return fmt.Errorf("found %d violation(s) of the conventional commit specification", violations)
+	}
+	return nil
+}
+
+// lintTypes are the commit types known to the conventional commit convention and the ones declared by the config.
+func lintTypes(cfg config.Config) []conventional.CommitType {
+	types := append([]conventional.CommitType{}, conventional.KnownTypes...)
+	known := make(map[conventional.CommitType]bool)
+	for _, t := range types {
+		known[t] = true
+	}
+	for _, names := range cfg.Bumps {
+		for _, name := range names {
+			if t := conventional.CommitType(name); !known[t] {
+				types = append(types, t)
+				known[t] = true
+			}
+		}
+	}
+	return types
+}
+
+// printViolations prints each violation prefixed by the source of the message and returns how many were printed.
+func printViolations(w io.Writer, source string, violations []conventional.Violation) int {
+	for _, v := range violations {
+		_, _ = fmt.Fprintf(w, "%s: %s\n", source, v)
+	}
+	return len(violations)
+}
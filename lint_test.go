@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/hooliganlin/versioning/semversioner/config"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintTypes(t *testing.T) {
+	cfg := config.Config{Bumps: map[string][]string{"patch": {"fix", "deps"}}}
+	types := lintTypes(cfg)
+	assert.Len(t, types, len(conventional.KnownTypes)+1)
+	assert.Contains(t, types, conventional.CommitType("deps"))
+}
+
+func TestPrintViolations(t *testing.T) {
+	var b bytes.Buffer
+	n := printViolations(&b, "abc1234", conventional.Lint("feat!(api): x", conventional.KnownTypes))
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "abc1234: misplaced-breaking: \"feat!(api)\" must have a single ! right before the colon, ie. feat(scope)!:\n", b.String())
+
+	b.Reset()
+	n = printViolations(&b, "abc1234", conventional.Lint("feat(api): x", conventional.KnownTypes))
+	assert.Equal(t, 0, n)
+	assert.Empty(t, b.String())
+}
+
+func TestLintRangeIgnoresBranchPolicies(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "-C", dir, "init", "--quiet").CombinedOutput(); err != nil {
+		t.Fatalf("could not initialize git repository err=%v out=%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".semversioner.yaml"), []byte("branches:\n  - pattern: main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := git.New(dir)
+	_, err := g.CreateCommit(ctx, "feat: first", "", true)
+	assert.NoError(t, err)
+	_, err = g.CreateCommit(ctx, "feta: second", "", true)
+	assert.NoError(t, err)
+	if out, err := exec.Command("git", "-C", dir, "checkout", "--quiet", "--detach").CombinedOutput(); err != nil {
+		t.Fatalf("could not detach HEAD err=%v out=%s", err, out)
+	}
+	// a detached HEAD outside of a CI has no branch
+	for _, name := range git.BranchEnvVars {
+		t.Setenv(name, "")
+	}
+	defer func(workDir string) { opts.WorkDir = workDir }(opts.WorkDir)
+	opts.WorkDir = dir
+
+	err = (&LintCommand{Range: "HEAD~1..HEAD"}).Execute(nil)
+	assert.EqualError(t, err, "found 1 violation(s) of the conventional commit specification")
+	err = (&LintCommand{Range: "HEAD~1"}).Execute(nil)
+	assert.NoError(t, err)
+}
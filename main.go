@@ -1,50 +1,143 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/hooliganlin/versioning/semversioner/changelog"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/release"
 	"github.com/jessevdk/go-flags"
 	"log"
 	"os"
+	"time"
 )
 
+// Output formats
 const (
-	Patch = "patch"
-	Minor = "minor"
-	Major = "major"
-	Conventional = "conventional"
+	OutputText = "text"
+	OutputJSON = "json"
 )
 
+// ExitNoRelease is the exit status when the conventional commits since the latest tag don't warrant a release.
+const ExitNoRelease = 3
+
+// ExitTimeout is the exit status when git doesn't answer within the --timeout.
+const ExitTimeout = 4
+
 type Opts struct {
 	WorkDir			string 	`long:"directory" description:"Working directory of a git repository" default:"."`
-	Type        	string 	`long:"type" description:"The release type" choice:"major" choice:"minor" choice:"patch" choice:"conventional"`
+	Type        	string 	`long:"type" description:"The release type, defaults to the type of the branch policy or config file" choice:"major" choice:"minor" choice:"patch" choice:"conventional" choice:"snapshot" choice:"graduate" choice:"pseudo"`
 	Prerelease  	string  `long:"prerelease" description:"The name of the pre-release (ie. alpha, rc)"`
+	PrereleaseCounter	bool	`long:"prerelease-counter" description:"Number the pre-release after the existing tags of the same version (ie. rc.1, rc.2)"`
+	FinalBase		bool	`long:"final-base" description:"Base the version on the latest final release, pre-release tags are ignored"`
+	Snapshot		bool	`long:"snapshot" description:"Name the next version of the release type a snapshot of the commits since the latest tag (ie. 1.3.0-SNAPSHOT.4+fb067b1)"`
+	SnapshotCount	string	`long:"snapshot-count" description:"The part of a snapshot version the number of commits since the latest tag goes to" choice:"prerelease" choice:"build" choice:"none" default:"prerelease"`
+	SnapshotHash	string	`long:"snapshot-hash" description:"The part of a snapshot version the abbreviated commit hash goes to" choice:"prerelease" choice:"build" choice:"none" default:"build"`
+	DirtyHash		bool	`long:"dirty-hash" description:"Add a short hash of the uncommitted changes to the dirty marker of snapshots (ie. dirty.3a4b5c6)"`
+	Apply			bool	`long:"apply" description:"Create an annotated git tag for the computed version, only for patch, minor, major, conventional and graduate releases"`
+	Changelog		bool	`long:"changelog" description:"Print the Markdown release notes of the next version instead of the version"`
+	Explain			bool	`long:"explain" description:"Print why the conventional commits since the latest tag lead to the bump to stderr"`
+	Output			string	`long:"output" description:"The output format" choice:"text" choice:"json" default:"text"`
+	Module			string	`long:"module" description:"Directory of a nested Go module relative to the repository root. Its tags are prefixed by the directory (ie. sub/dir/v1.2.3)"`
+	Ref				string	`long:"ref" description:"Determine the version of a commit, branch or tag instead of HEAD, without checking it out"`
+	GitBackend		string	`long:"git-backend" description:"How tags and commits are read: by running git, or in-process from the .git directory" choice:"exec" choice:"native" default:"exec"`
+	Timeout			time.Duration	`long:"timeout" description:"Kill git and fail when the version isn't determined in time (ie. 30s), no timeout by default"`
+
+	Modules			ModulesCommand	`command:"modules" description:"List the next version of every Go module in the repository"`
+	Lint			LintCommand		`command:"lint" description:"Validate commit messages against the conventional commit specification"`
+}
+
+var opts Opts
+
+// releaseOptions converts the command line options to the options of release.Next.
+func (o Opts) releaseOptions() release.Options {
+	return release.Options{
+		WorkDir:           o.WorkDir,
+		Type:              o.Type,
+		Prerelease:        o.Prerelease,
+		PrereleaseCounter: o.PrereleaseCounter,
+		FinalBase:         o.FinalBase,
+		Snapshot:          o.Snapshot,
+		SnapshotCount:     o.SnapshotCount,
+		SnapshotHash:      o.SnapshotHash,
+		DirtyHash:         o.DirtyHash,
+		Module:            o.Module,
+		Apply:             o.Apply,
+		Backend:           o.GitBackend,
+		Ref:               o.Ref,
+	}
+}
+
+// context returns the context of the commands, which is done after the Timeout when set.
+func (o Opts) context() (context.Context, context.CancelFunc) {
+	if o.Timeout > 0 {
+		return context.WithTimeout(context.Background(), o.Timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// exitOnTimeout exits with ExitTimeout when err is caused by the --timeout.
+func exitOnTimeout(err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("[ERROR] timed out after %s err=%v", opts.Timeout, err)
+		os.Exit(ExitTimeout)
+	}
 }
 
 func main() {
-	var opts Opts
-	_, err := flags.ParseArgs(&opts, os.Args)
-	if err != nil {
+	parser := flags.NewParser(&opts, flags.HelpFlag|flags.PassDoubleDash)
+	parser.SubcommandsOptional = true
+	if _, err := parser.ParseArgs(os.Args[1:]); err != nil {
+		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
+			fmt.Println(err)
+			return
+		}
+		exitOnTimeout(err)
+		if parser.Active != nil {
+			log.Fatalf("%s failed err=%v", parser.Active.Name, err)
+		}
 		log.Fatalf("could not parse %v", err)
 	}
-
-	g := git.New(opts.WorkDir)
-	if !g.IsValidGitDir() {
-		log.Fatalf("no valid git repo for working directory: %s", opts.WorkDir)
+	if parser.Active != nil {
+		// the command has already been executed by the parser
+		return
 	}
 
-	latestTag, err := g.GetLatestTag()
+	ctx, cancel := opts.context()
+	r, err := release.Next(ctx, opts.releaseOptions())
+	cancel()
 	if err != nil {
-		log.Fatalf("could not fetch latest tag err: %v", err)
+		exitOnTimeout(err)
+		log.Fatal(err)
 	}
-	v := newVersioner(g)
-	version := v.getVersion(opts.Type, latestTag)
 
-	if opts.Prerelease != "" {
-		version, err = version.SetPrerelease(opts.Prerelease)
-		if err != nil {
-			log.Fatalf("could not set pre release name err=%v", err)
+	if r.Override != nil {
+		log.Printf("[INFO] %s overrides the versioning rules", r.Override)
+	}
+	if opts.Explain && r.Type == release.Conventional {
+		e := conventional.Explain(r.Commits, r.Rules, r.PreviousTag)
+		if err = printExplanation(os.Stderr, r.PreviousTag, e); err != nil {
+			log.Fatalf("could not print explanation err=%v", err)
 		}
 	}
-	fmt.Println(version.String())
+
+	switch {
+	case opts.Output == OutputJSON:
+		if err = newReport(r).print(os.Stdout); err != nil {
+			log.Fatalf("could not print report err=%v", err)
+		}
+	case !r.Release:
+		log.Printf("[INFO] no release, the latest version %s stays current", r.Version.String())
+	case opts.Changelog:
+		fmt.Print(changelog.Generate(r.Version, r.Commits))
+	case r.Type == release.Pseudo:
+		// the go command only accepts versions with the v
+		fmt.Println("v" + r.Version.String())
+	default:
+		fmt.Println(r.Version.String())
+	}
+	if !r.Release {
+		os.Exit(ExitNoRelease)
+	}
 }
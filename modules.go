@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"github.com/hooliganlin/versioning/semversioner/release"
+	"os"
+	"text/tabwriter"
+)
+
+// ModulesCommand lists the next version of every Go module in the repository.
+type ModulesCommand struct{}
+
+// Execute prints the directory and next tag of every module, ie.
+//	.          v1.3.0
+//	api        api/v0.4.0
+//	tools/gen  tools/gen/v0.1.1  no release
+func (c *ModulesCommand) Execute(_ []string) error {
+	ctx, cancel := opts.context()
+	defer cancel()
+	results, err := release.Modules(ctx, opts.releaseOptions())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, r := range results {
+		if !r.Release {
+			_, _ = fmt.Fprintf(w, "%s\t%s\tno release\n", r.Module.Dir, r.Tag)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", r.Module.Dir, r.Tag)
+	}
+	return w.Flush()
+}
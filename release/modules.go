@@ -0,0 +1,50 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/gomodule"
+)
+
+// ModuleResult is the next version of a Go module of the repository.
+type ModuleResult struct {
+	Module gomodule.Module
+	Result
+}
+
+// Modules determines the next version of every Go module in the repository, see Next. The options' Module and Apply
+// are ignored.
+func Modules(ctx context.Context, opts Options) ([]ModuleResult, error) {
+	g, _, _, err := Load(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	root, err := g.TopLevel(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the root of the repository err=%w", err)
+	}
+	modules, err := gomodule.Discover(root)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ModuleResult, 0, len(modules))
+	for _, m := range modules {
+		o := opts
+		o.Module, o.Apply = m.Dir, false
+		r, err := Next(ctx, o)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine the next version of module=%s err=%w", m.Dir, err)
+		}
+		results = append(results, ModuleResult{Module: m, Result: r})
+	}
+	return results, nil
+}
+
+// moduleRules scopes the rules to the tags and files of the module m.
+func moduleRules(rules conventional.Rules, m gomodule.Module) conventional.Rules {
+	rules.TagPrefix = m.TagPrefix() + rules.TagPrefix
+	rules.Paths = m.Pathspecs()
+	return rules
+}
@@ -0,0 +1,52 @@
+package release
+
+import (
+	"context"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/gomodule"
+	"os"
+	"path/filepath"
+)
+
+func (s *VersionerTestSuite) TestModuleVersion() {
+	ctx := context.Background()
+	for _, dir := range []string{".", "api"} {
+		if err := os.MkdirAll(filepath.Join(s.Git.WorkDirectory, dir), 0755); err != nil {
+			s.FailNow("could not create module directory", err)
+		}
+		if err := os.WriteFile(filepath.Join(s.Git.WorkDirectory, dir, "go.mod"), []byte("module example.com/x"), 0644); err != nil {
+			s.FailNow("could not create go.mod", err)
+		}
+	}
+	if err := s.Git.Add(ctx, "."); err != nil {
+		s.FailNow("could not stage modules", err)
+	}
+	_, _ = s.Git.CreateCommit(ctx, "feat: init", "", false)
+	for _, tag := range []string{"v1.0.0", "api/v0.1.0"} {
+		if err := s.Git.CreateTag(ctx, tag, false); err != nil {
+			s.FailNow("could not create tag", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(s.Git.WorkDirectory, "api", "api.go"), []byte("package api"), 0644); err != nil {
+		s.FailNow("could not create file", err)
+	}
+	if err := s.Git.Add(ctx, "api"); err != nil {
+		s.FailNow("could not stage file", err)
+	}
+	_, _ = s.Git.CreateCommit(ctx, "feat(api): new endpoint", "", false)
+
+	modules, err := gomodule.Discover(s.Git.WorkDirectory)
+	if err != nil {
+		s.FailNow("could not discover modules", err)
+	}
+	s.Len(modules, 2)
+
+	root := newVersioner(s.Git, moduleRules(conventional.DefaultRules(), modules[0]))
+	_, err = root.nextVersion(ctx, Conventional, "", false)
+	s.ErrorIs(err, conventional.ErrNoRelease)
+
+	api := newVersioner(s.Git, moduleRules(conventional.DefaultRules(), modules[1]))
+	version, err := api.nextVersion(ctx, Conventional, "", false)
+	s.NoError(err)
+	s.Equal("api/v0.2.0", api.rules.FormatTag(version))
+}
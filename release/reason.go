@@ -0,0 +1,76 @@
+package release
+
+import (
+	"fmt"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+)
+
+// bumpReason describes why the version of the releaseType was bumped.
+func bumpReason(rules conventional.Rules, releaseType string, previousTag string,
+	commits []conventional.Commit) (conventional.Bump, string) {
+	switch releaseType {
+	case Patch, Minor, Major:
+		bump, _ := conventional.ParseBump(releaseType)
+		return bump, fmt.Sprintf("release type %s", releaseType)
+	case Graduate:
+		return conventional.BumpMajor, fmt.Sprintf("graduation of %s to 1.0.0", previousTag)
+	case Conventional:
+		commits = conventional.DropReverted(commits)
+		if o, ok := conventional.FindReleaseAs(commits); ok {
+			return releaseAsBump(rules, previousTag, o), o.String()
+		}
+		if previousTag == "" {
+			return conventional.BumpPatch, fmt.Sprintf("first release after the initial version %s", rules.InitialVersion)
+		}
+		bump := conventional.DetermineBump(commits, rules)
+		c, ok := conventional.DecisiveCommit(commits, rules, bump)
+		if !ok {
+			return conventional.BumpNone, fmt.Sprintf("no commit since %s warrants a release", previousTag)
+		}
+		reason := fmt.Sprintf("%s commit %s", c.Type, c.ShortHash())
+		if o, ok := findOverride(rules, releaseType, previousTag, commits); ok {
+			reason = o.String()
+		} else if c.IsBreaking {
+			reason = fmt.Sprintf("breaking change in commit %s", c.ShortHash())
+		}
+		if v, err := rules.ParseTag(previousTag); err == nil {
+			if lowered := rules.VersionBump(*v, bump); lowered != bump {
+				return lowered, fmt.Sprintf("%s, lowered to a %s while the major is 0", reason, lowered)
+			}
+		}
+		return bump, reason
+	default:
+		return conventional.BumpNone, fmt.Sprintf("snapshot of %s", previousTag)
+	}
+}
+
+// findOverride returns the trailer overriding the rules of a conventional release. Before the first tag only a
+// Release-As trailer does, the first release is a patch of the initial version otherwise.
+func findOverride(rules conventional.Rules, releaseType string, previousTag string,
+	commits []conventional.Commit) (conventional.Override, bool) {
+	if releaseType != Conventional {
+		return conventional.Override{}, false
+	}
+	commits = conventional.DropReverted(commits)
+	if previousTag == "" {
+		return conventional.FindReleaseAs(commits)
+	}
+	return conventional.FindOverride(commits, rules)
+}
+
+// releaseAsBump returns the part of the version the Release-As override increments from the previous tag, or from the
+// initial version before the first tag.
+func releaseAsBump(rules conventional.Rules, previousTag string, o conventional.Override) conventional.Bump {
+	if previousTag == "" {
+		previousTag = rules.TagPrefix + rules.InitialVersion
+	}
+	previous, err := rules.ParseTag(previousTag)
+	if err != nil {
+		return conventional.BumpNone
+	}
+	next, err := o.Version()
+	if err != nil {
+		return conventional.BumpNone
+	}
+	return conventional.BumpBetween(*previous, *next)
+}
@@ -0,0 +1,62 @@
+package release
+
+import (
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBumpReason(t *testing.T) {
+	rules := conventional.DefaultRules()
+	commits := conventional.ParseCommits([]git.Commit{
+		{Subject: "fix: fix 1", Hash: "aaaaaaaaaa"},
+		{Subject: "feat(api): feature 1", Hash: "bbbbbbbbbb"},
+		{Subject: "chore: chore 1", Hash: "cccccccccc"},
+	})
+
+	bump, reason := bumpReason(rules, Conventional, "v1.0.0", commits)
+	assert.Equal(t, conventional.BumpMinor, bump)
+	assert.Equal(t, "feat commit bbbbbbb", reason)
+
+	commits = append(commits, conventional.NewCommit(git.Commit{Subject: "fix!: drop v1", Hash: "dddddddddd"}))
+	bump, reason = bumpReason(rules, Conventional, "v1.0.0", commits)
+	assert.Equal(t, conventional.BumpMajor, bump)
+	assert.Equal(t, "breaking change in commit ddddddd", reason)
+
+	rules.InitialDevelopment = true
+	bump, reason = bumpReason(rules, Conventional, "v0.4.2", commits)
+	assert.Equal(t, conventional.BumpMinor, bump)
+	assert.Equal(t, "breaking change in commit ddddddd, lowered to a minor while the major is 0", reason)
+
+	bump, reason = bumpReason(rules, Graduate, "v0.4.2", commits)
+	assert.Equal(t, conventional.BumpMajor, bump)
+	assert.Equal(t, "graduation of v0.4.2 to 1.0.0", reason)
+	rules.InitialDevelopment = false
+
+	bump, reason = bumpReason(rules, Conventional, "v1.0.0", commits[2:3])
+	assert.Equal(t, conventional.BumpNone, bump)
+	assert.Equal(t, "no commit since v1.0.0 warrants a release", reason)
+
+	bump, reason = bumpReason(rules, Conventional, "", commits)
+	assert.Equal(t, conventional.BumpPatch, bump)
+	assert.Equal(t, "first release after the initial version 0.0.0", reason)
+
+	override := conventional.NewCommit(git.Commit{Subject: "docs: docs 1", Body: "Semver-Bump: major", Hash: "eeeeeeeeee"})
+	bump, reason = bumpReason(rules, Conventional, "v1.0.0", append(commits[:2:2], override))
+	assert.Equal(t, conventional.BumpMajor, bump)
+	assert.Equal(t, "Semver-Bump: major in commit eeeeeee", reason)
+
+	release := conventional.NewCommit(git.Commit{Subject: "chore: release", Body: "Release-As: 1.1.0", Hash: "ffffffffff"})
+	bump, reason = bumpReason(rules, Conventional, "v1.0.0", append(commits, release))
+	assert.Equal(t, conventional.BumpMinor, bump)
+	assert.Equal(t, "Release-As: 1.1.0 in commit fffffff", reason)
+
+	bump, reason = bumpReason(rules, Minor, "v1.0.0", nil)
+	assert.Equal(t, conventional.BumpMinor, bump)
+	assert.Equal(t, "release type minor", reason)
+
+	bump, reason = bumpReason(rules, Snapshot, "v1.0.0-2-abcdefg", nil)
+	assert.Equal(t, conventional.BumpNone, bump)
+	assert.Equal(t, "snapshot of v1.0.0-2-abcdefg", reason)
+}
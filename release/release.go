@@ -0,0 +1,289 @@
+// Package release determines the next semantic version of a git repository, the logic behind the versioner command.
+// Embed it instead of running the command, ie.
+//	r, err := release.Next(ctx, release.Options{WorkDir: ".", Type: release.Conventional})
+//	if err != nil {
+//		return err
+//	}
+//	if r.Release {
+//		fmt.Println(r.Tag, r.Bump, r.Reason)
+//	}
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/hooliganlin/versioning/semversioner/config"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/hooliganlin/versioning/semversioner/gomodule"
+	"strings"
+)
+
+// Release types
+const (
+	Patch        = "patch"
+	Minor        = "minor"
+	Major        = "major"
+	Conventional = "conventional"
+	Snapshot     = "snapshot"
+	Graduate     = "graduate"
+	Pseudo       = "pseudo"
+)
+
+// ErrNotTaggable is returned when Apply is set for a version which must not become a tag. A snapshot tag would be the
+// latest tag every later version is based on.
+var ErrNotTaggable = errors.New("only patch, minor, major, conventional and graduate releases can be tagged")
+
+// ErrUnknownType is returned when the release type of the options, the branch policy or the config file is not one of
+// the release type constants.
+var ErrUnknownType = errors.New("unknown release type")
+
+// Parts of a snapshot version the commit count and hash go to
+const (
+	PartPrerelease = "prerelease"
+	PartBuild      = "build"
+	PartNone       = "none"
+)
+
+// Options select how the next version is determined. Empty options keep the defaults of the config file and of the
+// branch policy.
+type Options struct {
+	// WorkDir is the working directory of the git repository.
+	WorkDir string
+	// Type is the release type, one of the release type constants. Snapshot is used when neither the options, the
+	// branch policy nor the config file set one.
+	Type string
+	// Prerelease is the name of the pre-release, ie. rc
+	Prerelease string
+	// PrereleaseCounter numbers the pre-release after the existing tags of the same version, ie. rc.1, rc.2
+	PrereleaseCounter bool
+	// FinalBase bases the version on the latest final release, pre-release tags are ignored.
+	FinalBase bool
+	// Snapshot names the next version of the Type a snapshot of the commits since the latest tag, ie.
+	// 1.3.0-SNAPSHOT.4+fb067b1
+	Snapshot bool
+	// SnapshotCount is the part of a snapshot the commit count goes to, PartPrerelease by default.
+	SnapshotCount string
+	// SnapshotHash is the part of a snapshot the commit hash goes to, PartBuild by default.
+	SnapshotHash string
+	// DirtyHash adds a short hash of the uncommitted changes to the dirty marker of snapshots.
+	DirtyHash bool
+	// Module is the directory of a Go module relative to the repository root. Its tags are prefixed by the directory.
+	Module string
+	// Apply creates an annotated tag for the next version when it is a release. Only the patch, minor, major,
+	// conventional and graduate types can be applied, ErrNotTaggable is returned for the others and for snapshots.
+	Apply bool
+	// Backend answers the tag and commit queries, git.BackendExec by default. git.BackendNative reads the repository
+	// in-process instead of running git.
+	Backend string
+	// Ref is the revision the version is determined for instead of HEAD, ie. a branch, a tag or a commit hash. The
+	// branch policy is the one of the branch it names and the Apply tag points at it.
+	Ref string
+}
+
+// Result is the next version and how it was determined.
+type Result struct {
+	// Version is the next version, or the latest version when Release is false.
+	Version semver.Version
+	// Tag is the tag name of the Version.
+	Tag string
+	// PreviousTag is the latest tag the Version is based on, empty before the first release.
+	PreviousTag string
+	// Release is false when the conventional commits since the PreviousTag don't warrant a release.
+	Release bool
+	// Type is the release type the Version was determined with.
+	Type string
+	// Bump is the part of the version incremented since the PreviousTag.
+	Bump conventional.Bump
+	// Reason describes why the version was bumped, ie. "feat commit c100381"
+	Reason string
+	// Override is the Release-As or Semver-Bump trailer overriding the rules, nil when the rules decide.
+	Override *conventional.Override
+	// Commits are the commits since the PreviousTag, newest first.
+	Commits []conventional.Commit
+	// Rules are the versioning rules the Version was determined with.
+	Rules conventional.Rules
+	// Applied is true when the Tag was created.
+	Applied bool
+}
+
+// Next determines the next version of the repository in the options' WorkDir. A conventional release without any
+// releasable commit is not an error, the Result is then not a Release.
+func Next(ctx context.Context, opts Options) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	g, rules, opts, err := Load(ctx, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	if opts.Apply && !taggable(opts) {
+		return Result{}, fmt.Errorf("%w: type=%s snapshot=%t", ErrNotTaggable, opts.Type, opts.Snapshot)
+	}
+
+	// the tag and commits are fetched once for the version, the result and the tag message, and before tagging, the
+	// new tag would leave none
+	scoped := rules.Scope(g)
+	previousTag, err := scoped.GetLatestTag(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("could not fetch latest tag err=%w", err)
+	}
+	gitCommits, err := scoped.GetCommitsSinceLatestTag(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("could not fetch commits since the latest tag err=%w", err)
+	}
+	commits := conventional.ParseCommits(gitCommits)
+
+	v := newVersioner(fetched{Repository: scoped, latestTag: previousTag, commits: gitCommits}, rules)
+	v.dirtyHash = opts.DirtyHash
+	var version semver.Version
+	if opts.Snapshot {
+		version, err = v.snapshotVersion(ctx, opts.Type, opts.SnapshotCount, opts.SnapshotHash)
+	} else {
+		version, err = v.nextVersion(ctx, opts.Type, opts.Prerelease, opts.PrereleaseCounter)
+	}
+	noRelease := errors.Is(err, conventional.ErrNoRelease)
+	if err != nil && !noRelease {
+		return Result{}, fmt.Errorf("could not determine the next version err=%w", err)
+	}
+
+	bump, reason := bumpReason(rules, opts.Type, previousTag, commits)
+	r := Result{
+		Version:     version,
+		Tag:         rules.FormatTag(version),
+		PreviousTag: previousTag,
+		Release:     !noRelease,
+		Type:        opts.Type,
+		Bump:        bump,
+		Reason:      reason,
+		Commits:     commits,
+		Rules:       rules,
+	}
+	if o, ok := findOverride(rules, opts.Type, previousTag, commits); ok {
+		r.Override = &o
+	}
+
+	if opts.Apply && r.Release {
+		if _, err = v.tag(ctx, version); err != nil {
+			return r, fmt.Errorf("could not create release tag err=%w", err)
+		}
+		r.Applied = true
+	}
+	return r, nil
+}
+
+// taggable reports whether the version the options determine can be tagged.
+func taggable(opts Options) bool {
+	switch opts.Type {
+	case Patch, Minor, Major, Conventional, Graduate:
+		return !opts.Snapshot
+	}
+	return false
+}
+
+// knownType reports whether releaseType is one of the release type constants.
+func knownType(releaseType string) bool {
+	switch releaseType {
+	case Patch, Minor, Major, Conventional, Snapshot, Graduate, Pseudo:
+		return true
+	}
+	return false
+}
+
+// Load validates the git repository of the options' WorkDir and loads its versioning rules from the config file, the
+// policy of the current branch and the Module. The returned git.Git is the repository root when a Module is set. The
+// returned options have the defaults of the config file and of the branch policy applied, options already set take
+// precedence.
+func Load(ctx context.Context, opts Options) (git.Git, conventional.Rules, Options, error) {
+	g := git.New(opts.WorkDir).WithBackend(opts.Backend).WithRef(opts.Ref)
+	if _, err := g.GitDir(ctx); err != nil {
+		return git.Git{}, conventional.Rules{}, opts, fmt.Errorf("could not open repository dir=%s err=%w", opts.WorkDir, err)
+	}
+	if opts.Ref != "" {
+		if strings.HasPrefix(opts.Ref, "-") {
+			return git.Git{}, conventional.Rules{}, opts, fmt.Errorf("invalid ref=%s", opts.Ref)
+		}
+		if _, err := g.GetHeadCommit(ctx); err != nil {
+			return git.Git{}, conventional.Rules{}, opts, fmt.Errorf("could not resolve ref=%s err=%w", opts.Ref, err)
+		}
+	}
+
+	cfg, err := config.Load(opts.WorkDir)
+	if err != nil {
+		return git.Git{}, conventional.Rules{}, opts, fmt.Errorf("could not load config err=%w", err)
+	}
+	rules, err := cfg.Rules()
+	if err != nil {
+		return git.Git{}, conventional.Rules{}, opts, fmt.Errorf("invalid config err=%w", err)
+	}
+	if len(cfg.Branches) > 0 {
+		if err = applyBranchPolicy(ctx, g, cfg, &opts, &rules); err != nil {
+			return git.Git{}, conventional.Rules{}, opts, fmt.Errorf("could not apply branch policy err=%w", err)
+		}
+	}
+	if opts.Type == "" {
+		opts.Type = cfg.Type
+	}
+	if opts.Snapshot && opts.Prerelease != "" {
+		return git.Git{}, conventional.Rules{}, opts,
+			fmt.Errorf("a snapshot names the pre-release, it can't be combined with prerelease=%s", opts.Prerelease)
+	}
+	if opts.Snapshot && (opts.Type == "" || opts.Type == Snapshot) {
+		opts.Type = Conventional
+	}
+	if opts.Type == "" {
+		opts.Type = Snapshot
+	}
+	if !knownType(opts.Type) {
+		return git.Git{}, conventional.Rules{}, opts, fmt.Errorf("%w: %s", ErrUnknownType, opts.Type)
+	}
+	if opts.SnapshotCount == "" {
+		opts.SnapshotCount = PartPrerelease
+	}
+	if opts.SnapshotHash == "" {
+		opts.SnapshotHash = PartBuild
+	}
+	// numbered pre-releases are based on the latest final release
+	rules.ExcludePrereleases = opts.FinalBase || opts.PrereleaseCounter
+
+	if opts.Module != "" {
+		root, err := g.TopLevel(ctx)
+		if err != nil {
+			return git.Git{}, conventional.Rules{}, opts, fmt.Errorf("could not find the root of the repository err=%w", err)
+		}
+		m, err := gomodule.Find(root, opts.Module)
+		if err != nil {
+			return git.Git{}, conventional.Rules{}, opts, fmt.Errorf("could not find module err=%w", err)
+		}
+		g = git.New(root).WithBackend(opts.Backend).WithRef(opts.Ref)
+		rules = moduleRules(rules, m)
+	}
+	return g, rules, opts, nil
+}
+
+// applyBranchPolicy applies the policy matching the current branch to the options and rules. Options already set take
+// precedence over the policy.
+func applyBranchPolicy(ctx context.Context, g git.Git, cfg config.Config, opts *Options, rules *conventional.Rules) error {
+	branch, err := g.CurrentBranch(ctx)
+	if err != nil {
+		return err
+	}
+	policy, ok, err := cfg.BranchPolicy(branch)
+	if err != nil || !ok {
+		return err
+	}
+
+	if opts.Type == "" {
+		opts.Type = policy.Type
+	}
+	if opts.Prerelease == "" {
+		opts.Prerelease = policy.Prerelease
+		opts.PrereleaseCounter = opts.PrereleaseCounter || policy.PrereleaseCounter
+	}
+	if policy.MaxBump != "" {
+		rules.MaxBump, err = conventional.ParseBump(policy.MaxBump)
+	}
+	return err
+}
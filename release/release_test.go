@@ -0,0 +1,213 @@
+package release
+
+import (
+	"context"
+	"github.com/hooliganlin/versioning/semversioner/config"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+func (s *VersionerTestSuite) TestApplyBranchPolicy() {
+	ctx := context.Background()
+	cfg := config.Config{Branches: []config.Branch{
+		{Pattern: "develop", Type: Conventional, Prerelease: "beta", PrereleaseCounter: true},
+		{Pattern: "release/*", Type: Conventional, MaxBump: "patch"},
+	}}
+	_, _ = s.Git.CreateCommit(ctx, "feat: feature 1", "", true)
+
+	if err := exec.Command("git", "-C", s.Git.WorkDirectory, "checkout", "-q", "-b", "develop").Run(); err != nil {
+		s.FailNow("could not create branch", err)
+	}
+	opts := Options{}
+	rules := conventional.DefaultRules()
+	s.NoError(applyBranchPolicy(ctx, s.Git, cfg, &opts, &rules))
+	s.Equal(Options{Type: Conventional, Prerelease: "beta", PrereleaseCounter: true}, opts)
+	s.Equal(conventional.DefaultRules(), rules)
+
+	opts = Options{Type: Major, Prerelease: "rc"}
+	s.NoError(applyBranchPolicy(ctx, s.Git, cfg, &opts, &rules))
+	s.Equal(Options{Type: Major, Prerelease: "rc"}, opts)
+
+	if err := exec.Command("git", "-C", s.Git.WorkDirectory, "checkout", "-q", "-b", "release/1.x").Run(); err != nil {
+		s.FailNow("could not create branch", err)
+	}
+	opts = Options{}
+	s.NoError(applyBranchPolicy(ctx, s.Git, cfg, &opts, &rules))
+	s.Equal(Options{Type: Conventional}, opts)
+	s.Equal(conventional.BumpPatch, rules.MaxBump)
+}
+
+func (s *VersionerTestSuite) TestNext() {
+	ctx := context.Background()
+	_, _ = s.Git.CreateCommit(ctx, "feat: feature 1", "", true)
+	if err := s.Git.CreateTag(ctx, "v1.2.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	_, _ = s.Git.CreateCommit(ctx, "chore: chore 1", "", true)
+
+	r, err := Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: Conventional, Apply: true})
+	s.NoError(err)
+	s.False(r.Release)
+	s.False(r.Applied)
+	s.Equal("1.2.0", r.Version.String())
+	s.Equal(conventional.BumpNone, r.Bump)
+
+	c, _ := s.Git.CreateCommit(ctx, "feat(api): feature 2", "", true)
+	r, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: Conventional, Apply: true})
+	s.NoError(err)
+	s.True(r.Release)
+	s.True(r.Applied)
+	s.Equal("1.3.0", r.Version.String())
+	s.Equal("v1.3.0", r.Tag)
+	s.Equal("v1.2.0", r.PreviousTag)
+	s.Equal(conventional.BumpMinor, r.Bump)
+	s.Equal("feat commit "+c.ShortHash(), r.Reason)
+	s.Len(r.Commits, 2)
+	tag, err := s.Git.GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("v1.3.0", tag)
+
+	r, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory})
+	s.NoError(err)
+	s.Equal(Snapshot, r.Type)
+
+	_, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Snapshot: true, Prerelease: "rc"})
+	s.Error(err)
+
+	// snapshots are never tagged
+	_, _ = s.Git.CreateCommit(ctx, "fix: fix 1", "", true)
+	_, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Apply: true})
+	s.ErrorIs(err, ErrNotTaggable)
+	_, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: Conventional, Snapshot: true, Apply: true})
+	s.ErrorIs(err, ErrNotTaggable)
+	// neither are pseudo-versions, every later version would be based on them
+	_, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: Pseudo, Apply: true})
+	s.ErrorIs(err, ErrNotTaggable)
+	tag, err = s.Git.GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("v1.3.0", tag)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Next(cancelled, Options{WorkDir: s.Git.WorkDirectory})
+	s.ErrorIs(err, context.Canceled)
+}
+
+func (s *VersionerTestSuite) TestNextMaxBump() {
+	ctx := context.Background()
+	config := filepath.Join(s.Git.WorkDirectory, ".semversioner.yaml")
+	if err := os.WriteFile(config, []byte("branches:\n  - pattern: release/*\n    maxBump: patch\n"), 0644); err != nil {
+		s.FailNow("could not write config file", err)
+	}
+	_ = s.Git.Add(ctx, ".semversioner.yaml")
+	_, _ = s.Git.CreateCommit(ctx, "feat: feature 1", "", true)
+	if err := s.Git.CreateTag(ctx, "v1.2.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if err := exec.Command("git", "-C", s.Git.WorkDirectory, "checkout", "-q", "-b", "release/1.x").Run(); err != nil {
+		s.FailNow("could not create branch", err)
+	}
+
+	_, _ = s.Git.CreateCommit(ctx, "feat: feature 2", "", true)
+	_, err := Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: Conventional, Apply: true})
+	s.ErrorIs(err, conventional.ErrBumpNotAllowed)
+	// a Release-As trailer doesn't lift the limit
+	_, _ = s.Git.CreateCommit(ctx, "chore: release", "Release-As: 2.0.0", true)
+	_, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: Conventional, Apply: true})
+	s.ErrorIs(err, conventional.ErrBumpNotAllowed)
+	tag, err := s.Git.GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("v1.2.0", tag)
+}
+
+func (s *VersionerTestSuite) TestNextWithRef() {
+	ctx := context.Background()
+	_, _ = s.Git.CreateCommit(ctx, "feat: feature 1", "", true)
+	if err := s.Git.CreateTag(ctx, "v1.2.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	if err := exec.Command("git", "-C", s.Git.WorkDirectory, "checkout", "-q", "-b", "develop").Run(); err != nil {
+		s.FailNow("could not create branch", err)
+	}
+	c, _ := s.Git.CreateCommit(ctx, "feat(api): feature 2", "", true)
+	if err := exec.Command("git", "-C", s.Git.WorkDirectory, "checkout", "-q", "-").Run(); err != nil {
+		s.FailNow("could not checkout branch", err)
+	}
+	_, _ = s.Git.CreateCommit(ctx, "fix: fix 1", "", true)
+	if err := os.WriteFile(filepath.Join(s.Git.WorkDirectory, ".semversioner.yaml"), []byte("branches:\n  - pattern: develop\n    prerelease: beta\n"), 0644); err != nil {
+		s.FailNow("could not write config", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.Git.WorkDirectory, "develop"), []byte("todo"), 0644); err != nil {
+		s.FailNow("could not write file", err)
+	}
+
+	// the develop branch is released with its policy without checking it out, even next to a file of the same name
+	r, err := Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: Conventional, Ref: "develop", Apply: true})
+	s.NoError(err)
+	s.Equal("1.3.0-beta", r.Version.String())
+	s.Equal(conventional.BumpMinor, r.Bump)
+	s.Equal("feat commit "+c.ShortHash(), r.Reason)
+	s.True(r.Applied)
+	tag, err := s.Git.WithRef("develop").GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("v1.3.0-beta", tag)
+	tag, err = s.Git.GetLatestTag(ctx)
+	s.NoError(err)
+	s.Equal("v1.2.0", tag)
+
+	// a tag is like a detached HEAD, the CI names the branch
+	for _, name := range git.BranchEnvVars {
+		s.T().Setenv(name, "")
+	}
+	s.T().Setenv("GITHUB_REF_NAME", "main")
+	r, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: Conventional, Ref: "v1.2.0"})
+	s.NoError(err)
+	s.False(r.Release)
+
+	_, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Ref: "unknown"})
+	s.ErrorAs(err, new(git.ErrGitFailed))
+	_, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Ref: "--output=log.txt"})
+	s.EqualError(err, "invalid ref=--output=log.txt")
+}
+
+func (s *VersionerTestSuite) TestNextErrors() {
+	ctx := context.Background()
+	_, _ = s.Git.CreateCommit(ctx, "feat: feature 1", "", true)
+
+	_, err := Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: Minor})
+	s.ErrorIs(err, git.ErrNoTags)
+	_, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: Snapshot})
+	s.ErrorIs(err, git.ErrNoTags)
+	_, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: Conventional})
+	s.NoError(err, "the first conventional release doesn't need a tag")
+
+	// a misspelled type is not a snapshot, wherever it comes from
+	_, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory, Type: "conventinal"})
+	s.ErrorIs(err, ErrUnknownType)
+	s.EqualError(err, "unknown release type: conventinal")
+	config := filepath.Join(s.Git.WorkDirectory, ".semversioner.yaml")
+	for _, file := range []string{"type: conventinal\n", "branches:\n  - pattern: \"*\"\n    type: majr\n"} {
+		if err = os.WriteFile(config, []byte(file), 0644); err != nil {
+			s.FailNow("could not write config file", err)
+		}
+		_, err = Next(ctx, Options{WorkDir: s.Git.WorkDirectory})
+		s.ErrorIs(err, ErrUnknownType, file)
+	}
+	if err = os.Remove(config); err != nil {
+		s.FailNow("could not remove config file", err)
+	}
+
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		s.FailNow("could not create temporary directory", err)
+	}
+	defer os.RemoveAll(tempDir)
+	_, err = Next(ctx, Options{WorkDir: tempDir})
+	s.ErrorIs(err, git.ErrNotARepository)
+	s.ErrorAs(err, new(git.ErrGitFailed), "git explains the failure")
+	_, err = Next(ctx, Options{WorkDir: filepath.Join(tempDir, "missing")})
+	s.ErrorIs(err, git.ErrNotARepository)
+}
@@ -0,0 +1,334 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"strconv"
+	"strings"
+)
+
+type versioner struct {
+	git git.Repository
+	rules conventional.Rules
+	// dirtyHash adds a short hash of the uncommitted changes to the dirty marker of snapshots.
+	dirtyHash bool
+}
+func newVersioner(g git.Repository, rules conventional.Rules) versioner {
+	return versioner{
+		git: rules.Scope(g),
+		rules: rules,
+	}
+}
+
+// fetched answers the latest tag and commits queries with the answers of the Repository fetched already, so that a
+// version and the result describing it are based on the same answers without asking git twice.
+type fetched struct {
+	git.Repository
+	latestTag string
+	commits   []git.Commit
+}
+
+func (f fetched) GetLatestTag(context.Context) (string, error) {
+	return f.latestTag, nil
+}
+
+func (f fetched) GetCommitsSinceLatestTag(context.Context) ([]git.Commit, error) {
+	return f.commits, nil
+}
+
+func (f fetched) ForEachCommitSinceLatestTag(_ context.Context, fn func(git.Commit) error) error {
+	for _, c := range f.commits {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithScope keeps the answers, they were fetched in the scope of the rules the versioning scopes the Repository to.
+func (f fetched) WithScope(git.Scope) git.Repository {
+	return f
+}
+
+// getVersion determines the next version of the releaseType from the latest tag. For conventional releases without
+// any releasable commits the latest version is returned with conventional.ErrNoRelease. Release types other than
+// conventional and pseudo return git.ErrNoTags without a tag to base the version on.
+func(v versioner) getVersion(ctx context.Context, releaseType string, tag string) (semver.Version, error) {
+	switch releaseType {
+	case Patch, Minor, Major:
+		if tag == "" {
+			return semver.Version{}, noTags(releaseType)
+		}
+		version, err := v.rules.ParseTag(tag)
+		if err != nil {
+			return semver.Version{}, fmt.Errorf("could not parse tag=%s err=%w", tag, err)
+		}
+		bump, _ := conventional.ParseBump(releaseType)
+		if err = v.rules.CheckBump(bump); err != nil {
+			return semver.Version{}, err
+		}
+		return bump.Apply(*version), nil
+	case Graduate:
+		if tag == "" {
+			return semver.Version{}, noTags(releaseType)
+		}
+		version, err := v.rules.ParseTag(tag)
+		if err != nil {
+			return semver.Version{}, fmt.Errorf("could not parse tag=%s err=%w", tag, err)
+		}
+		return conventional.Graduate(*version)
+	case Pseudo:
+		return v.pseudoVersion(ctx)
+	case Conventional:
+		version, err := conventional.DetermineNextVersion(ctx, v.git, v.rules)
+		if err != nil && !errors.Is(err, conventional.ErrNoRelease) {
+			return semver.Version{}, fmt.Errorf("could not determine next version by conventional commits err=%w", err)
+		}
+		return version, err
+	default:
+		latestTag, err := v.git.GetLatestPreReleaseTag(ctx)
+		if err != nil {
+			return semver.Version{}, fmt.Errorf("could not get the latest pre release tag (snapshot) err=%w", err)
+		}
+		if latestTag == "" {
+			return semver.Version{}, noTags(Snapshot)
+		}
+		marker, err := v.dirtyMarker(ctx, "-")
+		if err != nil {
+			return semver.Version{}, err
+		}
+		version, err := v.rules.ParseTag(fmt.Sprintf("%s%s-%s", latestTag, marker, "SNAPSHOT"))
+		if err != nil {
+			return semver.Version{}, fmt.Errorf("could not parse tag=%s err=%w", latestTag, err)
+		}
+		return *version, nil
+	}
+}
+
+// noTags is the error of a releaseType based on the latest tag in a repository without tags.
+func noTags(releaseType string) error {
+	return fmt.Errorf("%w: a %s release is based on the latest tag, create one first", git.ErrNoTags, releaseType)
+}
+
+// nextVersion determines the next version of the releaseType from the latest tag and names it as the prerelease
+// when set. With counter the prerelease is numbered after the existing tags of the version (ie. rc.1, rc.2).
+func (v versioner) nextVersion(ctx context.Context, releaseType string, prerelease string, counter bool) (semver.Version, error) {
+	latestTag, err := v.git.GetLatestTag(ctx)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("could not fetch latest tag err=%w", err)
+	}
+	version, err := v.getVersion(ctx, releaseType, latestTag)
+	if err != nil {
+		return version, err
+	}
+
+	if prerelease != "" && counter {
+		return v.numberPrerelease(ctx, version, prerelease)
+	}
+	if prerelease != "" {
+		version, err = version.SetPrerelease(prerelease)
+		if err != nil {
+			return semver.Version{}, fmt.Errorf("could not set pre release name err=%w", err)
+		}
+	}
+	return version, nil
+}
+
+// snapshotVersion names the next version of the releaseType a snapshot of the commits since the latest tag, ie.
+// 1.3.0-SNAPSHOT.4+fb067b1 for the 4th commit at fb067b1. The commit count and hash go to the countPart and hashPart
+// of the version (prerelease, build or none). A dirty working tree adds a dirty marker after the hash, ie.
+// 1.3.0-SNAPSHOT.4+fb067b1.dirty When the commits don't warrant a release the snapshot is of the next
+// patch, so that it still sorts above the latest release. After a pre-release tag of the same version the snapshot
+// extends its pre-release, ie. 0.2.0-rc.1.SNAPSHOT.4 after v0.2.0-rc.1, which 0.2.0-SNAPSHOT.4 would sort below.
+func (v versioner) snapshotVersion(ctx context.Context, releaseType string, countPart string, hashPart string) (semver.Version, error) {
+	version, err := v.nextVersion(ctx, releaseType, "", false)
+	if errors.Is(err, conventional.ErrNoRelease) {
+		version, err = conventional.BumpPatch.Apply(version), nil
+	}
+	if err != nil {
+		return semver.Version{}, err
+	}
+	d, err := v.git.Describe(ctx)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("could not describe HEAD err=%w", err)
+	}
+
+	marker, err := v.dirtyMarker(ctx, ".")
+	if err != nil {
+		return semver.Version{}, err
+	}
+
+	base, err := v.prereleaseBase(ctx, version)
+	if err != nil {
+		return semver.Version{}, err
+	}
+
+	parts := map[string][]string{PartPrerelease: {"SNAPSHOT"}}
+	if base != "" {
+		parts[PartPrerelease] = []string{base, "SNAPSHOT"}
+	}
+	parts[countPart] = append(parts[countPart], strconv.Itoa(d.Distance))
+	parts[hashPart] = append(parts[hashPart], d.Hash)
+	if marker != "" {
+		// the dirty marker follows the hash it qualifies
+		markerPart := hashPart
+		if markerPart == PartNone {
+			markerPart = PartPrerelease
+		}
+		parts[markerPart] = append(parts[markerPart], strings.TrimPrefix(marker, "."))
+	}
+	if version, err = version.SetPrerelease(strings.Join(parts[PartPrerelease], ".")); err != nil {
+		return semver.Version{}, fmt.Errorf("could not set pre release name err=%w", err)
+	}
+	if version, err = version.SetMetadata(strings.Join(parts[PartBuild], ".")); err != nil {
+		return semver.Version{}, fmt.Errorf("could not set build metadata err=%w", err)
+	}
+	return version, nil
+}
+
+// prereleaseBase returns the pre-release of the latest tag when the tag is a pre-release of version, ie. rc.1 for
+// 0.2.0 after v0.2.0-rc.1. It is empty otherwise.
+func (v versioner) prereleaseBase(ctx context.Context, version semver.Version) (string, error) {
+	tag, err := v.git.GetLatestTag(ctx)
+	if err != nil || tag == "" {
+		return "", err
+	}
+	latest, err := v.rules.ParseTag(tag)
+	if err != nil {
+		return "", fmt.Errorf("could not parse tag=%s err=%w", tag, err)
+	}
+	if latest.Prerelease() == "" || conventional.BumpBetween(*latest, version) != conventional.BumpNone {
+		return "", nil
+	}
+	return latest.Prerelease(), nil
+}
+
+// pseudoVersion returns the Go module pseudo-version of HEAD, made of the UTC commit time and 12 character hash of HEAD
+// on top of the latest tag, ie.
+//	0.0.0-20211116213829-fb067b14f2e9        without a tag
+//	1.2.4-0.20211116213829-fb067b14f2e9      after v1.2.3
+//	1.3.0-rc.1.0.20211116213829-fb067b14f2e9 after v1.3.0-rc.1
+// The version of the latest tag is returned when it points at HEAD.
+func (v versioner) pseudoVersion(ctx context.Context) (semver.Version, error) {
+	d, err := v.git.Describe(ctx)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("could not describe HEAD err=%w", err)
+	}
+	head, err := v.git.GetHeadCommit(ctx)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("could not fetch HEAD commit err=%w", err)
+	}
+	revision := fmt.Sprintf("%s-%.12s", head.Date.UTC().Format("20060102150405"), head.Hash)
+	if d.Tag == "" {
+		version, err := semver.NewVersion("v0.0.0-" + revision)
+		if err != nil {
+			return semver.Version{}, fmt.Errorf("could not build pseudo-version err=%w", err)
+		}
+		return *version, nil
+	}
+
+	base, err := v.rules.ParseTag(d.Tag)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("could not parse tag=%s err=%w", d.Tag, err)
+	}
+	version, err := base.SetMetadata("")
+	if err != nil || d.Distance == 0 {
+		return version, err
+	}
+	prerelease := version.Prerelease() + ".0." + revision
+	if version.Prerelease() == "" {
+		version, prerelease = version.IncPatch(), "0."+revision
+	}
+	if version, err = version.SetPrerelease(prerelease); err != nil {
+		return semver.Version{}, fmt.Errorf("could not build pseudo-version err=%w", err)
+	}
+	return version, nil
+}
+
+// dirtyMarker returns the marker of a snapshot of a dirty working tree, ie. -dirty or -dirty-3a4b5c6 with the
+// dirtyHash, its parts are joined by the separator. The marker is empty for a clean working tree.
+func (v versioner) dirtyMarker(ctx context.Context, separator string) (string, error) {
+	dirty, err := v.git.IsDirty(ctx)
+	if err != nil || !dirty {
+		return "", err
+	}
+	if !v.dirtyHash {
+		return separator + "dirty", nil
+	}
+	hash, err := v.git.DiffHash(ctx)
+	if err != nil {
+		return "", err
+	}
+	return separator + "dirty" + separator + hash, nil
+}
+
+// numberPrerelease names version as the next numbered pre-release of the channel, ie. 1.3.0-rc.2 when the tag
+// v1.3.0-rc.1 exists.
+func (v versioner) numberPrerelease(ctx context.Context, version semver.Version, channel string) (semver.Version, error) {
+	base, err := version.SetPrerelease("")
+	if err != nil {
+		return semver.Version{}, err
+	}
+	tags, err := v.git.ListTags(ctx, fmt.Sprintf("%s-%s.*", v.rules.FormatTag(base), channel))
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("could not list pre release tags err=%w", err)
+	}
+
+	latest := 0
+	for _, t := range tags {
+		tv, err := v.rules.ParseTag(t)
+		if err != nil {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(tv.Prerelease(), channel+"."))
+		if err == nil && n > latest {
+			latest = n
+		}
+	}
+
+	version, err = base.SetPrerelease(fmt.Sprintf("%s.%d", channel, latest+1))
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("could not set pre release name err=%w", err)
+	}
+	return version, nil
+}
+
+// tag creates an annotated git tag for version and returns its name. The tag message summarises the commits since the
+// previous tag. A dirty working tree is not tagged, the tag would not point at what was built, git.ErrDirty is
+// returned.
+func (v versioner) tag(ctx context.Context, version semver.Version) (string, error) {
+	dirty, err := v.git.IsDirty(ctx)
+	if err != nil {
+		return "", err
+	}
+	if dirty {
+		return "", git.ErrDirty
+	}
+	commits, err := v.git.GetCommitsSinceLatestTag(ctx)
+	if err != nil {
+		return "", err
+	}
+	name := v.rules.FormatTag(version)
+	return name, v.git.CreateAnnotatedTag(ctx, name, tagMessage(version, commits))
+}
+
+// tagMessage builds the message of a release tag, ie.
+//	Release 1.2.0
+//
+//	- fb067b1 feat(scope): this is a new feature
+//	- c100381 fix: this is a fix
+func tagMessage(version semver.Version, commits []git.Commit) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Release %s\n", version.String()))
+	if len(commits) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, c := range commits {
+		sb.WriteString(fmt.Sprintf("- %s %s\n", c.ShortHash(), c.Subject))
+	}
+	return sb.String()
+}
@@ -0,0 +1,317 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/semver"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func(s *VersionerTestSuite) TestGetVersion()  {
+	ctx := context.Background()
+	repo := git.NewMemory()
+	v := newVersioner(repo, conventional.DefaultRules())
+
+	version, err := v.getVersion(ctx, Major, "v2.3.4")
+	s.NoError(err)
+	s.Equal(semver.MustParse("v3.0.0"), &version)
+
+	version, err = v.getVersion(ctx, Minor, "v2.3.4")
+	s.NoError(err)
+	s.Equal(semver.MustParse("v2.4.0"), &version)
+
+	version, err = v.getVersion(ctx, Patch, "v2.3.4")
+	s.NoError(err)
+	s.Equal(semver.MustParse("v2.3.5"), &version)
+
+	_, err = v.getVersion(ctx, Patch, "deploy-prod")
+	s.Error(err)
+
+	version, err = v.getVersion(ctx, Graduate, "v0.4.2")
+	s.NoError(err)
+	s.Equal("1.0.0", version.String())
+
+	_, err = v.getVersion(ctx, Graduate, "v2.3.4")
+	s.ErrorIs(err, conventional.ErrGraduated)
+
+	_, _ = repo.CreateCommit(ctx, "feat: feature 1", "this is body", true)
+	err = repo.CreateTag(ctx, "v0.0.1", false)
+	if err != nil {
+		s.FailNow("np no no nonono")
+	}
+
+	_, err = v.getVersion(ctx, Conventional, "v0.0.1")
+	s.ErrorIs(err, conventional.ErrNoRelease)
+
+	_, _ = repo.CreateCommit(ctx, "feat: feature 2", "", true)
+	c, _ := repo.CreateCommit(ctx, "chore: chore 1", "", true)
+	tag, err := v.git.GetLatestTag(ctx)
+	if err != nil {
+		s.FailNow("np no no nonono")
+	}
+
+	version, err = v.getVersion(ctx, Conventional, tag)
+	s.NoError(err)
+	s.Equal(semver.MustParse("v0.1.0"), &version)
+
+	version, err = v.getVersion(ctx, "snapshot", tag)
+	s.NoError(err)
+	s.Equal(semver.MustParse(fmt.Sprintf("v0.0.1-%d-%s-SNAPSHOT", 2, c.Hash[:7])), &version)
+}
+
+func (s *VersionerTestSuite) TestTag() {
+	ctx := context.Background()
+	v := newVersioner(s.Git, conventional.DefaultRules())
+	_, _ = s.Git.CreateCommit(ctx, "feat: feature 1", "", true)
+	if err := s.Git.CreateTag(ctx, "v0.0.1", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	c, _ := s.Git.CreateCommit(ctx, "fix: fix 1", "", true)
+
+	version, err := v.getVersion(ctx, Patch, "v0.0.1")
+	if err != nil {
+		s.FailNow("could not get version", err)
+	}
+	name, err := v.tag(ctx, version)
+	if err != nil {
+		s.FailNow("could not create release tag", err)
+	}
+	s.Equal("v0.0.2", name)
+
+	tag, err := v.git.GetLatestTag(ctx)
+	if err != nil {
+		s.FailNow("could not get latest tag", err)
+	}
+	s.Equal("v0.0.2", tag)
+
+	out, err := exec.Command("git", "-C", s.Git.WorkDirectory, "tag", "-l", "--format=%(contents)", name).Output()
+	if err != nil {
+		s.FailNow("could not read tag message", err)
+	}
+	s.Equal(fmt.Sprintf("Release 0.0.2\n\n- %s fix: fix 1\n\n", c.Hash[:7]), string(out))
+}
+
+func (s *VersionerTestSuite) TestPrereleaseCounter() {
+	ctx := context.Background()
+	rules := conventional.DefaultRules()
+	rules.ExcludePrereleases = true
+	repo := git.NewMemory()
+	v := newVersioner(repo, rules)
+	_, _ = repo.CreateCommit(ctx, "feat: feature 1", "", true)
+	if err := repo.CreateTag(ctx, "v1.2.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	_, _ = repo.CreateCommit(ctx, "feat: feature 2", "", true)
+
+	version, err := v.nextVersion(ctx, Conventional, "rc", true)
+	s.NoError(err)
+	s.Equal("1.3.0-rc.1", version.String())
+	if _, err = v.tag(ctx, version); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+
+	_, _ = repo.CreateCommit(ctx, "fix: fix 1", "", true)
+	version, err = v.nextVersion(ctx, Conventional, "rc", true)
+	s.NoError(err)
+	s.Equal("1.3.0-rc.2", version.String())
+
+	version, err = v.nextVersion(ctx, Conventional, "beta", true)
+	s.NoError(err)
+	s.Equal("1.3.0-beta.1", version.String())
+
+	version, err = v.nextVersion(ctx, Conventional, "rc", false)
+	s.NoError(err)
+	s.Equal("1.3.0-rc", version.String())
+}
+
+func (s *VersionerTestSuite) TestSnapshotVersion() {
+	ctx := context.Background()
+	repo := git.NewMemory()
+	v := newVersioner(repo, conventional.DefaultRules())
+	_, _ = repo.CreateCommit(ctx, "feat: feature 1", "", true)
+	if err := repo.CreateTag(ctx, "v1.2.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+
+	version, err := v.snapshotVersion(ctx, Conventional, PartPrerelease, PartBuild)
+	s.NoError(err)
+	head, _ := v.git.Describe(ctx)
+	s.Equal(fmt.Sprintf("1.2.1-SNAPSHOT.0+%s", head.Hash), version.String())
+
+	_, _ = repo.CreateCommit(ctx, "feat: feature 2", "", true)
+	_, _ = repo.CreateCommit(ctx, "fix: fix 1", "", true)
+	c, _ := repo.CreateCommit(ctx, "chore: chore 1", "", true)
+	hash := c.ShortHash()
+
+	version, err = v.snapshotVersion(ctx, Conventional, PartPrerelease, PartBuild)
+	s.NoError(err)
+	s.Equal(fmt.Sprintf("1.3.0-SNAPSHOT.3+%s", hash), version.String())
+	s.True(version.GreaterThan(semver.MustParse("v1.2.0")))
+
+	version, err = v.snapshotVersion(ctx, Major, PartBuild, PartPrerelease)
+	s.NoError(err)
+	s.Equal(fmt.Sprintf("2.0.0-SNAPSHOT.%s+3", hash), version.String())
+
+	version, err = v.snapshotVersion(ctx, Patch, PartNone, PartNone)
+	s.NoError(err)
+	s.Equal("1.2.1-SNAPSHOT", version.String())
+}
+
+func (s *VersionerTestSuite) TestSnapshotVersionOfPrerelease() {
+	ctx := context.Background()
+	repo := git.NewMemory()
+	v := newVersioner(repo, conventional.DefaultRules())
+	_, _ = repo.CreateCommit(ctx, "feat: feature 1", "", true)
+	if err := repo.CreateTag(ctx, "v0.2.0-rc.1", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	rc := semver.MustParse("v0.2.0-rc.1")
+
+	// neither no releasable commits nor a fix bump past the release of the pre-release
+	for i, subject := range []string{"chore: chore 1", "fix: fix 1"} {
+		c, _ := repo.CreateCommit(ctx, subject, "", true)
+		version, err := v.snapshotVersion(ctx, Conventional, PartPrerelease, PartBuild)
+		s.NoError(err)
+		s.Equal(fmt.Sprintf("0.2.0-rc.1.SNAPSHOT.%d+%s", i+1, c.ShortHash()), version.String())
+		s.True(version.GreaterThan(rc))
+	}
+	version, err := v.snapshotVersion(ctx, Patch, PartNone, PartNone)
+	s.NoError(err)
+	s.Equal("0.2.0-rc.1.SNAPSHOT", version.String())
+	s.True(version.LessThan(semver.MustParse("v0.2.0-rc.2")))
+
+	// a feature is of the next minor
+	_, _ = repo.CreateCommit(ctx, "feat: feature 2", "", true)
+	version, err = v.snapshotVersion(ctx, Conventional, PartPrerelease, PartNone)
+	s.NoError(err)
+	s.Equal("0.3.0-SNAPSHOT.3", version.String())
+}
+
+func (s *VersionerTestSuite) TestDirtyWorkingTree() {
+	ctx := context.Background()
+	v := newVersioner(s.Git, conventional.DefaultRules())
+	_, _ = s.Git.CreateCommit(ctx, "feat: feature 1", "", true)
+	if err := s.Git.CreateTag(ctx, "v1.2.0", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	c, _ := s.Git.CreateCommit(ctx, "fix: fix 1", "", true)
+	if err := os.WriteFile(filepath.Join(s.Git.WorkDirectory, "notes.txt"), []byte("todo"), 0644); err != nil {
+		s.FailNow("could not write file", err)
+	}
+
+	version, err := v.getVersion(ctx, Snapshot, "v1.2.0")
+	s.NoError(err)
+	s.Equal(fmt.Sprintf("1.2.0-1-%s-dirty-SNAPSHOT", c.ShortHash()), version.String())
+
+	version, err = v.snapshotVersion(ctx, Conventional, PartPrerelease, PartBuild)
+	s.NoError(err)
+	s.Equal(fmt.Sprintf("1.2.1-SNAPSHOT.1+%s.dirty", c.ShortHash()), version.String())
+
+	v.dirtyHash = true
+	hash, err := v.git.DiffHash(ctx)
+	s.NoError(err)
+	version, err = v.snapshotVersion(ctx, Conventional, PartPrerelease, PartNone)
+	s.NoError(err)
+	s.Equal(fmt.Sprintf("1.2.1-SNAPSHOT.1.dirty.%s", hash), version.String())
+
+	_, err = v.tag(ctx, version)
+	s.ErrorIs(err, git.ErrDirty)
+	tags, err := v.git.ListTags(ctx, "v1.2.1*")
+	s.NoError(err)
+	s.Empty(tags)
+}
+
+func (s *VersionerTestSuite) TestPseudoVersion() {
+	ctx := context.Background()
+	repo := git.NewMemory()
+	v := newVersioner(repo, conventional.DefaultRules())
+	revision := func() string {
+		head, err := v.git.GetHeadCommit(ctx)
+		if err != nil {
+			s.FailNow("could not fetch HEAD commit", err)
+		}
+		return head.Date.UTC().Format("20060102150405") + "-" + head.Hash[:12]
+	}
+
+	_, _ = repo.CreateCommit(ctx, "feat: feature 1", "", true)
+	version, err := v.getVersion(ctx, Pseudo, "")
+	s.NoError(err)
+	s.Equal("0.0.0-"+revision(), version.String())
+
+	if err = repo.CreateTag(ctx, "v1.2.3+build.1", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	version, err = v.getVersion(ctx, Pseudo, "v1.2.3+build.1")
+	s.NoError(err)
+	s.Equal("1.2.3", version.String())
+
+	_, _ = repo.CreateCommit(ctx, "feat: feature 2", "", true)
+	version, err = v.getVersion(ctx, Pseudo, "v1.2.3+build.1")
+	s.NoError(err)
+	s.Equal("1.2.4-0."+revision(), version.String())
+
+	if err = repo.CreateTag(ctx, "v1.3.0-rc.1", false); err != nil {
+		s.FailNow("could not create tag", err)
+	}
+	_, _ = repo.CreateCommit(ctx, "fix: fix 1", "", true)
+	version, err = v.getVersion(ctx, Pseudo, "v1.3.0-rc.1")
+	s.NoError(err)
+	s.Equal("1.3.0-rc.1.0."+revision(), version.String())
+}
+
+func TestTagMessage(t *testing.T) {
+	version := *semver.MustParse("v1.2.0")
+	commits := []git.Commit{
+		{Subject: "feat(scope): this is a new feature", Hash: "fb067b14f2e9d24fee367651603424a8304a0845"},
+		{Subject: "fix: this is a fix", Hash: "c1003815c1cfe3c0cd718550573031a8bf536188"},
+	}
+	assert.Equal(t, "Release 1.2.0\n\n- fb067b1 feat(scope): this is a new feature\n- c100381 fix: this is a fix\n",
+		tagMessage(version, commits))
+	assert.Equal(t, "Release 1.2.0\n", tagMessage(version, nil))
+}
+
+func TestRunVersioner(t *testing.T) {
+	suite.Run(t, new(VersionerTestSuite))
+}
+
+type VersionerTestSuite struct {
+	suite.Suite
+	Git git.Git
+}
+
+func (s *VersionerTestSuite) SetupTest() {
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		s.FailNow("could not create temporary directory", err)
+	}
+	if err = os.Chdir(tempDir); err != nil {
+		s.FailNow("could not change to temporary directory", err)
+	}
+
+	if err != nil {
+		s.FailNow("cannot setup working directory", err)
+	}
+	if err = exec.Command("git", "init").Run(); err != nil {
+		s.FailNow("could not initialize git repository err", err)
+	}
+	if err != nil {
+		if err = os.RemoveAll(tempDir); err != nil {
+			s.FailNowf("could not remove working directory", "dir=%s err=%v", tempDir, err)
+		}
+		s.FailNow("cannot create new git repo", err)
+	}
+	s.Git = git.New(tempDir)
+}
+
+func (s *VersionerTestSuite) TearDownTest() {
+	if err := os.RemoveAll(s.Git.WorkDirectory); err != nil {
+		s.T().Fatalf("could not remove temporary directory %s err=%v", s.Git.WorkDirectory, err)
+	}
+}
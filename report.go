@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/hooliganlin/versioning/semversioner/release"
+	"io"
+	"time"
+)
+
+// report is the machine-readable outcome of determining the next version, printed by --output json.
+type report struct {
+	PreviousTag string `json:"previousTag"`
+	Version     string `json:"version"`
+	Tag         string `json:"tag"`
+	Major       int64  `json:"major"`
+	Minor       int64  `json:"minor"`
+	Patch       int64  `json:"patch"`
+	Prerelease  string `json:"prerelease"`
+	Release     bool   `json:"release"`
+	Type        string `json:"type"`
+	Bump        string `json:"bump"`
+	Reason      string `json:"reason"`
+	// Override is the Release-As or Semver-Bump trailer overriding the rules, only set when one does.
+	Override string         `json:"override,omitempty"`
+	Commits  []reportCommit `json:"commits"`
+}
+
+// reportCommit is a commit considered for the next version.
+type reportCommit struct {
+	Hash     string    `json:"hash"`
+	Author   string    `json:"author"`
+	Email    string    `json:"email"`
+	Date     time.Time `json:"date"`
+	Subject  string    `json:"subject"`
+	Type     string    `json:"type"`
+	Scope    string    `json:"scope"`
+	Breaking bool      `json:"breaking"`
+	// BreakingDescription is the description of the breaking change, only set for breaking commits.
+	BreakingDescription string `json:"breakingDescription,omitempty"`
+}
+
+// newReport converts the result of release.Next to a report.
+func newReport(r release.Result) report {
+	rep := report{
+		PreviousTag: r.PreviousTag,
+		Version:     r.Version.String(),
+		Tag:         r.Tag,
+		Major:       r.Version.Major(),
+		Minor:       r.Version.Minor(),
+		Patch:       r.Version.Patch(),
+		Prerelease:  r.Version.Prerelease(),
+		Release:     r.Release,
+		Type:        r.Type,
+		Bump:        r.Bump.String(),
+		Reason:      r.Reason,
+		Commits:     make([]reportCommit, 0, len(r.Commits)),
+	}
+	if r.Override != nil {
+		rep.Override = r.Override.String()
+	}
+	for _, c := range r.Commits {
+		rep.Commits = append(rep.Commits, reportCommit{
+			Hash:                c.Hash,
+			Author:              c.Author.Name,
+			Email:               c.Author.Email,
+			Date:                c.Date,
+			Subject:             c.Subject,
+			Type:                string(c.Type),
+			Scope:               c.Scope,
+			Breaking:            c.IsBreaking,
+			BreakingDescription: c.BreakingDescription,
+		})
+	}
+	return rep
+}
+
+// print writes the report as indented JSON.
+func (r report) print(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Masterminds/semver"
+	"github.com/hooliganlin/versioning/semversioner/conventional"
+	"github.com/hooliganlin/versioning/semversioner/git"
+	"github.com/hooliganlin/versioning/semversioner/release"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	date := time.Date(2021, 11, 16, 21, 38, 29, 0, time.UTC)
+	commits := conventional.ParseCommits([]git.Commit{{
+		Subject: "feat(scope): this is a new feature",
+		Hash:    "c1003815c1cfe3c0cd718550573031a8bf536188",
+		Author:  git.Author{Name: "Jane Doe", Email: "jane@mailinator.com"},
+		Date:    date,
+	}})
+
+	r := newReport(release.Result{
+		Version:     *semver.MustParse("v1.3.0-rc.1"),
+		Tag:         "v1.3.0-rc.1",
+		PreviousTag: "v1.2.3",
+		Release:     true,
+		Type:        release.Conventional,
+		Bump:        conventional.BumpMinor,
+		Reason:      "feat commit c100381",
+		Commits:     commits,
+	})
+	assert.Equal(t, report{
+		PreviousTag: "v1.2.3",
+		Version:     "1.3.0-rc.1",
+		Tag:         "v1.3.0-rc.1",
+		Major:       1,
+		Minor:       3,
+		Patch:       0,
+		Prerelease:  "rc.1",
+		Release:     true,
+		Type:        release.Conventional,
+		Bump:        "minor",
+		Reason:      "feat commit c100381",
+		Commits: []reportCommit{{
+			Hash:     "c1003815c1cfe3c0cd718550573031a8bf536188",
+			Author:   "Jane Doe",
+			Email:    "jane@mailinator.com",
+			Date:     date,
+			Subject:  "feat(scope): this is a new feature",
+			Type:     "feat",
+			Scope:    "scope",
+			Breaking: false,
+		}},
+	}, r)
+
+	var b bytes.Buffer
+	assert.NoError(t, r.print(&b))
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b.Bytes(), &decoded))
+	assert.Equal(t, "1.3.0-rc.1", decoded["version"])
+	assert.Equal(t, "2021-11-16T21:38:29Z", decoded["commits"].([]interface{})[0].(map[string]interface{})["date"])
+	assert.NotContains(t, decoded, "override")
+
+	o := conventional.Override{Token: conventional.ReleaseAsToken, Value: "2.0.0", Commit: commits[0]}
+	r = newReport(release.Result{Version: *semver.MustParse("v2.0.0"), Override: &o})
+	assert.Equal(t, "Release-As: 2.0.0 in commit c100381", r.Override)
+	assert.Equal(t, []reportCommit{}, r.Commits)
+}